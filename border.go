@@ -0,0 +1,127 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// BorderStyle is the set of characters a box border is drawn with. A zero
+// rune leaves that part of the border undrawn; the zero BorderStyle means the
+// default border.
+type BorderStyle struct {
+	Name                                       string // Identifies the predefined styles; custom styles may leave it empty
+	Top, Bottom, Left, Right                   rune
+	TopLeft, TopRight, BottomLeft, BottomRight rune
+	Grip                                       rune // Resize grip in the bottom-right corner of windows
+}
+
+// Border styles
+var (
+	BorderSingle = BorderStyle{Name: "single", Top: '─', Bottom: '─', Left: '│', Right: '│',
+		TopLeft: '┌', TopRight: '┐', BottomLeft: '└', BottomRight: '┘', Grip: '┼'}
+	BorderDouble = BorderStyle{Name: "double", Top: '═', Bottom: '═', Left: '║', Right: '║',
+		TopLeft: '╔', TopRight: '╗', BottomLeft: '╚', BottomRight: '╝', Grip: '╬'}
+	BorderRounded = BorderStyle{Name: "rounded", Top: '─', Bottom: '─', Left: '│', Right: '│',
+		TopLeft: '╭', TopRight: '╮', BottomLeft: '╰', BottomRight: '╯', Grip: '┼'}
+	BorderHeavy = BorderStyle{Name: "heavy", Top: '━', Bottom: '━', Left: '┃', Right: '┃',
+		TopLeft: '┏', TopRight: '┓', BottomLeft: '┗', BottomRight: '┛', Grip: '╋'}
+	BorderASCII = BorderStyle{Name: "ascii", Top: '-', Bottom: '-', Left: '|', Right: '|',
+		TopLeft: '+', TopRight: '+', BottomLeft: '+', BottomRight: '+', Grip: '#'}
+	BorderBlock = BorderStyle{Name: "block", Top: '▀', Bottom: '▄', Left: '▌', Right: '▐',
+		TopLeft: '▛', TopRight: '▜', BottomLeft: '▙', BottomRight: '▟', Grip: '█'}
+	BorderDoubleTop = BorderStyle{Name: "double-top", Top: '═', Bottom: '═', Left: '│', Right: '│',
+		TopLeft: '╒', TopRight: '╕', BottomLeft: '╘', BottomRight: '╛', Grip: '╪'}
+	BorderDoubleSide = BorderStyle{Name: "double-side", Top: '─', Bottom: '─', Left: '║', Right: '║',
+		TopLeft: '╓', TopRight: '╖', BottomLeft: '╙', BottomRight: '╜', Grip: '╫'}
+	BorderNone = BorderStyle{Name: "none", Top: ' ', Bottom: ' ', Left: ' ', Right: ' ',
+		TopLeft: ' ', TopRight: ' ', BottomLeft: ' ', BottomRight: ' ', Grip: ' '}
+)
+
+// BorderStyles returns the predefined border styles
+func BorderStyles() []BorderStyle {
+	return []BorderStyle{BorderSingle, BorderDouble, BorderRounded, BorderHeavy, BorderASCII,
+		BorderBlock, BorderDoubleTop, BorderDoubleSide, BorderNone}
+}
+
+// BorderByName returns the predefined border style with the given name
+func BorderByName(name string) (BorderStyle, bool) {
+	for _, b := range BorderStyles() {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return BorderStyle{}, false
+}
+
+// runes returns the characters of the border
+func (b BorderStyle) runes() []rune {
+	return []rune{b.Top, b.Bottom, b.Left, b.Right, b.TopLeft, b.TopRight, b.BottomLeft, b.BottomRight, b.Grip}
+}
+
+// Fallback returns b, or BorderASCII when s is a screen that cannot display
+// all of b's characters, such as a terminal without Unicode support
+func (b BorderStyle) Fallback(s Surface) BorderStyle {
+	screen, ok := s.(interface {
+		CanDisplay(r rune, checkFallbacks bool) bool
+	})
+	if !ok {
+		return b
+	}
+	for _, r := range b.runes() {
+		if r != 0 && r >= 0x80 && !screen.CanDisplay(r, false) {
+			return BorderASCII
+		}
+	}
+	return b
+}
+
+// border returns the border selected by the options: Border when set,
+// otherwise the single or double line set by DoubleLine
+func (options DrawOptions) border() BorderStyle {
+	switch {
+	case options.Border != BorderStyle{}:
+		return options.Border
+	case options.DoubleLine:
+		return BorderDouble
+	}
+	return BorderSingle
+}
+
+// DrawBorder draws the border of the box at (x, y) of size (w, h) in style,
+// clipped to the surface
+func DrawBorder(s Surface, x, y, w, h int, border BorderStyle, style tcell.Style) {
+	drawBorder(s, x, y, w, h, border, style, false)
+}
+
+// drawBorder draws a border; with merge, where it crosses lines already on
+// the surface it is joined to them with junction characters
+func drawBorder(s Surface, x, y, w, h int, border BorderStyle, style tcell.Style, merge bool) {
+	if w < 2 || h < 2 {
+		return
+	}
+	sw, sh := s.Size()
+	set := func(i, j int, r rune) {
+		if r == 0 || i < 0 || j < 0 || i >= sw || j >= sh {
+			return
+		}
+		if merge {
+			existing, _, _, _ := s.GetContent(i, j)
+			r = MergeRune(existing, r)
+		}
+		s.SetContent(i, j, r, nil, style)
+	}
+	// Top & bottom edges.
+	for i := max(1, -x); i < min(w-1, sw-x); i++ {
+		set(x+i, y, border.Top)
+		set(x+i, y+h-1, border.Bottom)
+	}
+	// Left & right edges.
+	for j := max(1, -y); j < min(h-1, sh-y); j++ {
+		set(x, y+j, border.Left)
+		set(x+w-1, y+j, border.Right)
+	}
+	// Corners.
+	set(x, y, border.TopLeft)
+	set(x+w-1, y, border.TopRight)
+	set(x, y+h-1, border.BottomLeft)
+	set(x+w-1, y+h-1, border.BottomRight)
+}
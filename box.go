@@ -34,6 +34,13 @@ func DefaultKeyConfig() KeyConfig {
 func HandleBasicNavigation(ev tcell.Event, keys KeyConfig) (bool, NavigationAction) {
 	switch e := ev.(type) {
 	case *tcell.EventKey:
+		// A keymap resolves named actions, including chords
+		if keys.Keymap != nil {
+			action, _ := keys.Keymap.Resolve(e)
+			nav := NavigationFor(action)
+			return nav == NavExit, nav
+		}
+
 		// Check for exit keys
 		if slices.Contains(keys.ExitKeys, e.Key()) {
 			return true, NavExit
@@ -89,58 +96,40 @@ func InitScreen() (tcell.Screen, error) {
 	return screen, nil
 }
 
-// DrawBox draws a box using Unicode characters:
-// if options.Doubleline is false it uses ┌, ┐, └, ┘, with horizontal (─) and vertical (│) edges, otherwise
-// it uses ╔, ╗, ╚, ╝ with horizontal (═) and vertical (║) edges.
+// DrawBox draws a box with the border selected by the options:
+// options.Border when set, otherwise ┌, ┐, └, ┘, with horizontal (─) and vertical (│) edges, or
+// with options.DoubleLine ╔, ╗, ╚, ╝ with horizontal (═) and vertical (║) edges.
+// Screens that cannot display the border characters get BorderASCII instead.
+// With options.MergeBorders the border joins lines it touches, forming ├, ┬, ╬ and the like.
 // It fills the interior with the background color (using the fill option).
 // The shadow is drawn if the ShadowEnabled option is set.
 // The border color is set using the borderColor parameter.
 // The background color is set using the bgColor parameter.
 // The box is drawn at the specified (x, y) position with the specified width (w) and height (h).
-func DrawBox(s tcell.Screen, x, y, w, h int, borderColor, bgColor tcell.Color, options DrawOptions) {
-	horizontalLine, verticalLine, topLeft, topRight, bottomLeft, bottomRight := '─', '│', '┌', '┐', '└', '┘'
-	if options.DoubleLine {
-		horizontalLine = '═'
-		verticalLine = '║'
-		topLeft = '╔'
-		topRight = '╗'
-		bottomLeft = '╚'
-		bottomRight = '╝'
-	}
-
+func DrawBox(s Surface, x, y, w, h int, borderColor, bgColor tcell.Color, options DrawOptions) {
 	if w < 2 || h < 2 {
 		return
 	}
+	// Everything is clipped to the surface, so boxes may hang off any edge
 	borderStyle := tcell.StyleDefault.Foreground(borderColor).Background(bgColor)
-	// Top & bottom edges.
-	for i := 1; i < w-1; i++ {
-		s.SetContent(x+i, y, horizontalLine, nil, borderStyle)
-		s.SetContent(x+i, y+h-1, horizontalLine, nil, borderStyle)
-	}
-	// Left & right edges.
-	for j := 1; j < h-1; j++ {
-		s.SetContent(x, y+j, verticalLine, nil, borderStyle)
-		s.SetContent(x+w-1, y+j, verticalLine, nil, borderStyle)
-	}
-	// Corners.
-	s.SetContent(x, y, topLeft, nil, borderStyle)
-	s.SetContent(x+w-1, y, topRight, nil, borderStyle)
-	s.SetContent(x, y+h-1, bottomLeft, nil, borderStyle)
-	s.SetContent(x+w-1, y+h-1, bottomRight, nil, borderStyle)
+	drawBorder(s, x, y, w, h, options.border().Fallback(s), borderStyle, options.MergeBorders)
 
 	// Fill interior.
 	fillStyle := tcell.StyleDefault.Background(bgColor)
 	fillChar := GetFillChar(options)
-	for j := y + 1; j < y+h-1; j++ {
-		for i := x + 1; i < x+w-1; i++ {
+	inner := clipRect(s, Rect{X: x + 1, Y: y + 1, Width: w - 2, Height: h - 2})
+	for j := inner.Y; j < inner.Y+inner.Height; j++ {
+		for i := inner.X; i < inner.X+inner.Width; i++ {
 			s.SetContent(i, j, fillChar, nil, fillStyle)
 		}
 	}
 
 	// Add shadow if enabled
 	if options.ShadowEnabled {
-		options.ShadowColor = tcell.ColorBlack
-		options.ShadowRune = '█'
+		if options.ShadowMode == ShadowSolid {
+			options.ShadowColor = tcell.ColorBlack
+			options.ShadowRune = '█'
+		}
 		DrawShadow(s, x, y, w, h, options)
 	}
 }
@@ -195,6 +184,16 @@ func ShowSelectionMenu(config MenuConfig) (int, string, error) {
 	screen.EnableMouse()
 	screen.Clear()
 
+	// RGB colors such as the default background are mapped to what the terminal can show
+	depth := config.ColorDepth
+	if depth == 0 {
+		depth = DetectColorDepth(screen)
+	}
+	screen = LimitColors(screen, depth)
+	if config.CGAPalette {
+		screen = ForceCGA(screen, config.BlinkMode)
+	}
+
 	// Initialize state
 	state := MenuState{
 		CurrentSelection: 0,
@@ -219,17 +218,31 @@ func ShowSelectionMenu(config MenuConfig) (int, string, error) {
 func handleEvents(s tcell.Screen, ev tcell.Event, config *MenuConfig, state *MenuState) bool {
 	switch e := ev.(type) {
 	case *tcell.EventKey:
-		// Check for exit keys
-		for _, key := range config.ExitKeys {
-			if e.Key() == key {
+		if config.Keymap != nil {
+			// The keymap decides which keys exit and navigate
+			action, pending := config.Keymap.Resolve(e, WidgetScope("list"))
+			if pending {
+				return true
+			}
+			if action == ActionExit {
 				return false
 			}
-		}
+			if key, ok := navKeys[action]; ok {
+				e = tcell.NewEventKey(key, 0, tcell.ModNone)
+			}
+		} else {
+			// Check for exit keys
+			for _, key := range config.ExitKeys {
+				if e.Key() == key {
+					return false
+				}
+			}
 
-		// Check for exit runes
-		for _, r := range config.ExitRunes {
-			if e.Rune() == r {
-				return false
+			// Check for exit runes
+			for _, r := range config.ExitRunes {
+				if e.Rune() == r {
+					return false
+				}
 			}
 		}
 
@@ -249,6 +262,12 @@ func handleEvents(s tcell.Screen, ev tcell.Event, config *MenuConfig, state *Men
 				state.CurrentSelection = 0
 			}
 			drawUI(s, config, state.CurrentSelection)
+		case tcell.KeyHome:
+			state.CurrentSelection = 0
+			drawUI(s, config, state.CurrentSelection)
+		case tcell.KeyEnd:
+			state.CurrentSelection = len(config.MenuItems) - 1
+			drawUI(s, config, state.CurrentSelection)
 		case tcell.KeyEnter:
 			// Mark item as selected
 			state.SelectedItem = config.MenuItems[state.CurrentSelection].Text
@@ -304,6 +323,10 @@ func handleEvents(s tcell.Screen, ev tcell.Event, config *MenuConfig, state *Men
 				}
 			}
 		}
+	case *tcell.EventResize:
+		HandleResize(s, e)
+		drawUI(s, config, state.CurrentSelection)
+
 	case *tcell.EventError:
 		s.Fini()
 		panic(e.Error())
@@ -323,13 +346,13 @@ func drawUI(s tcell.Screen, config *MenuConfig, selected int) {
 	s.Fill(GetFillChar(options), bgStyle)
 
 	// Title box
-	DrawTitleBox(s, config.AppName, config.CopyrightText, config.TitleBarFg, config.TitleBarBg)
+	DrawTitleBoxWithBorder(s, config.AppName, config.CopyrightText, config.TitleBorder, config.TitleBarFg, config.TitleBarBg)
 
 	// Selection dialog
-	DrawSelectionDialog(s, config.MenuItems, selected, config.MainSelectionBg, config.SelectionActiveBg, config.SelectionNumFg, config.SelectionTextFg)
+	DrawSelectionDialogWithBorder(s, config.MenuItems, selected, config.DialogBorder, config.MainSelectionBg, config.SelectionActiveBg, config.SelectionNumFg, config.SelectionTextFg)
 
 	// Instruction box
-	DrawInstructionBox(s, config.MenuItems, selected, config.DefaultInstructionText, config.InstructionBoxFg, config.InstructionBoxBg)
+	DrawInstructionBoxWithBorder(s, config.MenuItems, selected, config.DefaultInstructionText, config.DialogBorder, config.InstructionBoxFg, config.InstructionBoxBg)
 
 	// Status bar
 	statusText := "F3: Quit"
@@ -371,6 +394,11 @@ func DrawMessageBox(s tcell.Screen, message string, messageFg, messageBg, bgColo
 		switch ev.(type) {
 		case *tcell.EventKey, *tcell.EventMouse:
 			return
+		case *tcell.EventResize:
+			// Re-centre the message for the new screen size
+			HandleResize(s, ev)
+			s.Fill(GetFillChar(options), bgStyle)
+			DrawSimpleMessage(s, message, messageFg, messageBg)
 		}
 		ev = s.PollEvent()
 	}
@@ -0,0 +1,150 @@
+package retrotui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// recordingSurface is a canvas that counts the writes outside its bounds
+type recordingSurface struct {
+	*Canvas
+	outside int
+}
+
+func (r *recordingSurface) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	if w, h := r.Size(); x < 0 || y < 0 || x >= w || y >= h {
+		r.outside++
+		return
+	}
+	r.Canvas.SetContent(x, y, mainc, combc, style)
+}
+
+const (
+	screenW = 20
+	screenH = 10
+	margin  = 30 // Room around the reference surface that no test drawing reaches
+)
+
+// positions are box origins at, across and beyond every edge of the screen
+var positions = []struct{ x, y int }{
+	{-5, -3}, {-1, -1}, {0, 0}, {6, 2}, {15, 7}, {19, 9}, {-3, 6}, {16, -2}, {25, 3}, {4, 12}, {-12, -8},
+}
+
+// checkClipped draws with draw on a screen-sized surface and on a reference
+// surface with a margin around it, so nothing is clipped there, and fails if
+// anything is written outside the screen or a cell on the screen differs from
+// the reference
+func checkClipped(t *testing.T, draw func(s Surface, x, y int)) {
+	t.Helper()
+	under := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlue)
+
+	screen := &recordingSurface{Canvas: NewCanvas(screenW, screenH)}
+	screen.Fill('.', under)
+	ref := NewCanvas(screenW+2*margin, screenH+2*margin)
+	ref.Fill('.', under)
+
+	for _, p := range positions {
+		t.Run(fmt.Sprintf("%d,%d", p.x, p.y), func(t *testing.T) {
+			draw(screen, p.x, p.y)
+			draw(ref, p.x+margin, p.y+margin)
+			if screen.outside > 0 {
+				t.Fatalf("%d writes outside the screen", screen.outside)
+			}
+			for y := 0; y < screenH; y++ {
+				for x := 0; x < screenW; x++ {
+					got, _ := screen.at(x, y)
+					want, _ := ref.at(x+margin, y+margin)
+					if !got.equal(want) {
+						t.Fatalf("cell %d,%d is %q %v, want %q %v", x, y, got.mainc, got.style, want.mainc, want.style)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestClipRect(t *testing.T) {
+	s := NewCanvas(screenW, screenH)
+	tests := []struct {
+		r, want Rect
+	}{
+		{Rect{X: 2, Y: 3, Width: 4, Height: 5}, Rect{X: 2, Y: 3, Width: 4, Height: 5}},
+		{Rect{X: -2, Y: -1, Width: 4, Height: 5}, Rect{X: 0, Y: 0, Width: 2, Height: 4}},
+		{Rect{X: 18, Y: 8, Width: 4, Height: 5}, Rect{X: 18, Y: 8, Width: 2, Height: 2}},
+		{Rect{X: -5, Y: -5, Width: 40, Height: 40}, Rect{X: 0, Y: 0, Width: screenW, Height: screenH}},
+		{Rect{X: 20, Y: 0, Width: 4, Height: 5}, Rect{}},
+		{Rect{X: -4, Y: 0, Width: 4, Height: 5}, Rect{}},
+		{Rect{X: 0, Y: 10, Width: 4, Height: 5}, Rect{}},
+	}
+	for _, tt := range tests {
+		if got := clipRect(s, tt.r); got != tt.want {
+			t.Errorf("clipRect(%+v) = %+v, want %+v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestFillBoxClipped(t *testing.T) {
+	checkClipped(t, func(s Surface, x, y int) {
+		FillBox(s, x, y, 8, 5, tcell.ColorGreen, DrawOptions{FillPatternEnabled: true, FillRune: '▒'})
+	})
+}
+
+func TestDrawBoxClipped(t *testing.T) {
+	for _, border := range []BorderStyle{{}, BorderDouble, BorderRounded, BorderASCII} {
+		t.Run(border.Name, func(t *testing.T) {
+			checkClipped(t, func(s Surface, x, y int) {
+				DrawBox(s, x, y, 8, 5, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{Border: border})
+			})
+		})
+	}
+}
+
+func TestDrawBoxOnScreenEdges(t *testing.T) {
+	s := &recordingSurface{Canvas: NewCanvas(screenW, screenH)}
+	DrawBox(s, -1, -1, screenW+2, screenH+2, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{})
+	if s.outside > 0 {
+		t.Fatalf("%d writes outside the screen", s.outside)
+	}
+	// Only the interior is on screen
+	for y := 0; y < screenH; y++ {
+		for x := 0; x < screenW; x++ {
+			if r, _, _, _ := s.GetContent(x, y); r != ' ' {
+				t.Fatalf("cell %d,%d is %q, want the fill", x, y, r)
+			}
+		}
+	}
+}
+
+func TestDrawBoxSimulationScreen(t *testing.T) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	s.SetSize(screenW, screenH)
+
+	for _, p := range positions {
+		DrawBox(s, p.x, p.y, 8, 5, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{ShadowEnabled: true})
+		FillBox(s, p.x, p.y, 8, 5, tcell.ColorGreen, DrawOptions{})
+	}
+	s.Show()
+
+	DrawBox(s, 2, 1, 8, 5, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{})
+	if r, _, _, _ := s.GetContent(2, 1); r != '┌' {
+		t.Errorf("top-left corner is %q, want '┌'", r)
+	}
+	if r, _, _, _ := s.GetContent(9, 5); r != '┘' {
+		t.Errorf("bottom-right corner is %q, want '┘'", r)
+	}
+}
+
+func TestDrawBoxCustomBorder(t *testing.T) {
+	s := NewCanvas(screenW, screenH)
+	stars := BorderStyle{Top: '*', Bottom: '*', Left: '*', Right: '*', TopLeft: '+', TopRight: '+', BottomLeft: '+', BottomRight: '+'}
+	DrawBox(s, 0, 0, 4, 3, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{Border: stars, DoubleLine: true})
+	if got, want := s.String(), "+**+\n*  *\n+**+\n"; got[:len(want)] != want {
+		t.Errorf("box is\n%s, want\n%s", got, want)
+	}
+}
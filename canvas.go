@@ -0,0 +1,210 @@
+package retrotui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Surface is the part of tcell.Screen used by the drawing primitives such as
+// PrintAt, DrawBox and DrawShadow. A tcell.Screen is a Surface, and so is a
+// Canvas.
+type Surface interface {
+	SetContent(x, y int, primary rune, combining []rune, style tcell.Style)
+	GetContent(x, y int) (primary rune, combining []rune, style tcell.Style, width int)
+	Size() (width, height int)
+	Fill(r rune, style tcell.Style)
+}
+
+// cell is one character cell of a canvas. A zero mainc marks a cell that was
+// not drawn, through which whatever is below shows.
+type cell struct {
+	mainc rune
+	combc []rune
+	style tcell.Style
+}
+
+// equal reports whether two cells look the same on screen
+func (c cell) equal(o cell) bool {
+	return c.mainc == o.mainc && c.style == o.style && slices.Equal(c.combc, o.combc)
+}
+
+// Canvas is an in-memory grid of styled cells. Drawing into a canvas instead
+// of the screen lets components be rendered off-screen and then cached,
+// composited, scrolled by blitting part of the canvas, or exported as text.
+// Cells that were not drawn are transparent: Blit leaves the destination
+// alone there.
+type Canvas struct {
+	width  int
+	height int
+	cells  []cell
+	bounds Rect // Bounding box of the drawn cells
+}
+
+// NewCanvas creates a transparent canvas of the given size
+func NewCanvas(width, height int) *Canvas {
+	width, height = max(0, width), max(0, height)
+	return &Canvas{width: width, height: height, cells: make([]cell, width*height)}
+}
+
+// Size returns the size of the canvas
+func (c *Canvas) Size() (int, int) {
+	return c.width, c.height
+}
+
+// SetContent sets the cell at (x, y). Cells outside the canvas are ignored.
+func (c *Canvas) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return
+	}
+	if mainc == 0 {
+		mainc = ' '
+	}
+	c.cells[y*c.width+x] = cell{mainc: mainc, combc: slices.Clone(combc), style: style}
+	c.bounds = union(c.bounds, Rect{X: x, Y: y, Width: 1, Height: 1})
+}
+
+// GetContent returns the cell at (x, y). Transparent cells and cells outside
+// the canvas read as blanks.
+func (c *Canvas) GetContent(x, y int) (rune, []rune, tcell.Style, int) {
+	cl, ok := c.at(x, y)
+	if !ok {
+		return ' ', nil, tcell.StyleDefault, 1
+	}
+	return cl.mainc, cl.combc, cl.style, 1
+}
+
+// at returns the cell at (x, y) and whether it was drawn
+func (c *Canvas) at(x, y int) (cell, bool) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return cell{}, false
+	}
+	cl := c.cells[y*c.width+x]
+	return cl, cl.mainc != 0
+}
+
+// Fill sets every cell of the canvas
+func (c *Canvas) Fill(r rune, style tcell.Style) {
+	for y := 0; y < c.height; y++ {
+		for x := 0; x < c.width; x++ {
+			c.SetContent(x, y, r, nil, style)
+		}
+	}
+}
+
+// Clear makes every cell transparent again
+func (c *Canvas) Clear() {
+	b := c.bounds
+	for y := b.Y; y < b.Y+b.Height; y++ {
+		clear(c.cells[y*c.width+b.X : y*c.width+b.X+b.Width])
+	}
+	c.bounds = Rect{}
+}
+
+// Bounds returns the bounding box of the cells drawn since the last Clear
+func (c *Canvas) Bounds() Rect {
+	return c.bounds
+}
+
+// Resize changes the size of the canvas, keeping the cells that still fit
+func (c *Canvas) Resize(width, height int) {
+	width, height = max(0, width), max(0, height)
+	if width == c.width && height == c.height {
+		return
+	}
+	old := *c
+	*c = *NewCanvas(width, height)
+	for y := 0; y < min(height, old.height); y++ {
+		for x := 0; x < min(width, old.width); x++ {
+			if cl, ok := old.at(x, y); ok {
+				c.cells[y*width+x] = cl
+				c.bounds = union(c.bounds, Rect{X: x, Y: y, Width: 1, Height: 1})
+			}
+		}
+	}
+}
+
+// Blit copies the drawn cells of the src part of the canvas onto dst, with
+// the top-left corner of src at (x, y). The copy is clipped to the canvas and
+// to dst.
+func (c *Canvas) Blit(dst Surface, x, y int, src Rect) {
+	dw, dh := dst.Size()
+	for j := 0; j < src.Height; j++ {
+		for i := 0; i < src.Width; i++ {
+			tx, ty := x+i, y+j
+			if tx < 0 || ty < 0 || tx >= dw || ty >= dh {
+				continue
+			}
+			if cl, ok := c.at(src.X+i, src.Y+j); ok {
+				dst.SetContent(tx, ty, cl.mainc, cl.combc, cl.style)
+			}
+		}
+	}
+}
+
+// String returns the text of the canvas, one line per row, with transparent
+// cells as spaces and trailing spaces removed
+func (c *Canvas) String() string {
+	var b strings.Builder
+	for y := 0; y < c.height; y++ {
+		var line strings.Builder
+		for x := 0; x < c.width; x++ {
+			cl, ok := c.at(x, y)
+			if !ok {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteRune(cl.mainc)
+			for _, r := range cl.combc {
+				line.WriteRune(r)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Screen returns a tcell.Screen that draws into the canvas, for components
+// whose Draw takes a screen. Everything other than drawing, such as events
+// and color support, goes to base.
+func (c *Canvas) Screen(base tcell.Screen) tcell.Screen {
+	return &canvasScreen{Screen: base, canvas: c}
+}
+
+// canvasScreen directs drawing into a canvas. Reads of transparent cells go
+// to below when set, so that e.g. shadows see what they are drawn over.
+type canvasScreen struct {
+	tcell.Screen
+	canvas *Canvas
+	below  func(x, y int) cell
+}
+
+func (s *canvasScreen) Size() (int, int) {
+	return s.canvas.Size()
+}
+
+func (s *canvasScreen) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.canvas.SetContent(x, y, mainc, combc, style)
+}
+
+func (s *canvasScreen) GetContent(x, y int) (rune, []rune, tcell.Style, int) {
+	if _, ok := s.canvas.at(x, y); !ok && s.below != nil {
+		cl := s.below(x, y)
+		return cl.mainc, cl.combc, cl.style, 1
+	}
+	return s.canvas.GetContent(x, y)
+}
+
+func (s *canvasScreen) Fill(r rune, style tcell.Style) {
+	s.canvas.Fill(r, style)
+}
+
+func (s *canvasScreen) Clear() {
+	s.canvas.Fill(' ', tcell.StyleDefault)
+}
+
+// Show and Sync are left to whoever shows the canvas
+func (s *canvasScreen) Show() {}
+func (s *canvasScreen) Sync() {}
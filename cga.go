@@ -0,0 +1,130 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// cgaRGB marks a constant as an RGB color
+const cgaRGB = tcell.ColorValid | tcell.ColorIsRGB
+
+// The IBM CGA/EGA/VGA text-mode palette, in DOS attribute order
+const (
+	CGABlack        = cgaRGB | 0x000000
+	CGABlue         = cgaRGB | 0x0000AA
+	CGAGreen        = cgaRGB | 0x00AA00
+	CGACyan         = cgaRGB | 0x00AAAA
+	CGARed          = cgaRGB | 0xAA0000
+	CGAMagenta      = cgaRGB | 0xAA00AA
+	CGABrown        = cgaRGB | 0xAA5500 // Not dark yellow: the monitor halves the green
+	CGALightGray    = cgaRGB | 0xAAAAAA
+	CGADarkGray     = cgaRGB | 0x555555
+	CGALightBlue    = cgaRGB | 0x5555FF
+	CGALightGreen   = cgaRGB | 0x55FF55
+	CGALightCyan    = cgaRGB | 0x55FFFF
+	CGALightRed     = cgaRGB | 0xFF5555
+	CGALightMagenta = cgaRGB | 0xFF55FF
+	CGAYellow       = cgaRGB | 0xFFFF55
+	CGAWhite        = cgaRGB | 0xFFFFFF
+)
+
+// CGAPalette holds the 16 text-mode colors, indexed by their DOS attribute value
+var CGAPalette = []tcell.Color{
+	CGABlack, CGABlue, CGAGreen, CGACyan, CGARed, CGAMagenta, CGABrown, CGALightGray,
+	CGADarkGray, CGALightBlue, CGALightGreen, CGALightCyan, CGALightRed, CGALightMagenta, CGAYellow, CGAWhite,
+}
+
+// cgaANSI maps DOS attribute values to the ANSI palette, which swaps blue and red
+var cgaANSI = [16]int{0, 4, 2, 6, 1, 5, 3, 7, 8, 12, 10, 14, 9, 13, 11, 15}
+
+// BlinkMode selects what the blink bit of a text attribute does, like the
+// blink enable bit of the CGA/EGA/VGA mode control register
+type BlinkMode int
+
+const (
+	BlinkBright BlinkMode = iota // The bit brightens the background, giving 16 background colors
+	BlinkBlink                   // The bit makes text blink; backgrounds are limited to the 8 dark colors
+)
+
+// CGAIndex returns the DOS attribute value of the text-mode color nearest to c
+func CGAIndex(c tcell.Color) int {
+	nearest := tcell.FindColor(c, CGAPalette)
+	for i, p := range CGAPalette {
+		if p == nearest {
+			return i
+		}
+	}
+	return 0
+}
+
+// CGAColor returns the text-mode color nearest to c. The default color is
+// left alone.
+func CGAColor(c tcell.Color) tcell.Color {
+	if c == tcell.ColorDefault || c == tcell.ColorReset || !c.Valid() {
+		return c
+	}
+	return CGAPalette[CGAIndex(c)]
+}
+
+// CGAStyle returns style with its colors forced into the text-mode palette,
+// with the blink attribute treated as the blink bit of the DOS attribute
+// byte. With BlinkBright a blinking style gets the bright version of its
+// background and does not blink; with BlinkBlink a bright background is
+// darkened and the text blinks, as it did on real hardware.
+func CGAStyle(style tcell.Style, mode BlinkMode) tcell.Style {
+	fg, bg, attr := style.Decompose()
+	blink := attr&tcell.AttrBlink != 0
+	if fg != tcell.ColorDefault && fg != tcell.ColorReset {
+		fg = CGAPalette[CGAIndex(fg)]
+	}
+	if bg != tcell.ColorDefault && bg != tcell.ColorReset {
+		i := CGAIndex(bg)
+		switch {
+		case mode == BlinkBright && blink:
+			// The bit is used for intensity, so nothing blinks
+			i |= 8
+			blink = false
+		case mode == BlinkBlink && i >= 8:
+			i -= 8
+			blink = true
+		}
+		bg = CGAPalette[i]
+	}
+	return style.Foreground(fg).Background(bg).Blink(blink)
+}
+
+// CGA returns the theme with every color replaced by the nearest text-mode color
+func (t Theme) CGA() Theme {
+	fallbacks := t.Fallbacks
+	t = t.mapColors(CGAColor)
+	t.Fallbacks = make(map[ColorDepth]Theme, len(fallbacks))
+	for d, f := range fallbacks {
+		t.Fallbacks[d] = f.CGA()
+	}
+	return t
+}
+
+// ForceCGA returns a screen that draws on s with every color forced through
+// the text-mode palette by CGAStyle. A limit applied to s by LimitColors is
+// kept and applies to the text-mode colors, so e.g. monochrome still wins.
+// Terminals without true color get the matching ANSI palette colors.
+func ForceCGA(s tcell.Screen, mode BlinkMode) tcell.Screen {
+	styled := styleScreen{Screen: s, depth: DepthTrueColor}
+	if prev, ok := s.(*styleScreen); ok {
+		styled = *prev
+	}
+	styled.cga, styled.blink = true, mode
+	return &styled
+}
+
+// cgaToANSI replaces the text-mode colors of style with the matching colors
+// of the ANSI palette
+func cgaToANSI(style tcell.Style) tcell.Style {
+	fg, bg, _ := style.Decompose()
+	if fg.IsRGB() {
+		style = style.Foreground(tcell.PaletteColor(cgaANSI[CGAIndex(fg)]))
+	}
+	if bg.IsRGB() {
+		style = style.Background(tcell.PaletteColor(cgaANSI[CGAIndex(bg)]))
+	}
+	return style
+}
@@ -0,0 +1,163 @@
+package retrotui
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// PasteTarget is an input widget that accepts pasted text
+type PasteTarget interface {
+	InsertText(text string)
+}
+
+// Clipboard copies text to the terminal's clipboard with OSC 52 escape
+// sequences, which also works over SSH, and delivers bracketed pastes to the
+// focused input. A copy of the text is always kept in process, so copy and
+// paste work within the application when the terminal has no clipboard
+// support.
+//
+// Give the clipboard the first chance at events: during a bracketed paste the
+// pasted characters arrive as ordinary key events.
+type Clipboard struct {
+	OSC52  bool        // Write to the terminal clipboard; see DetectOSC52
+	Target PasteTarget // Receives pasted text, usually the focused input
+
+	mu      sync.Mutex
+	text    string          // In-process clipboard
+	pasting bool            // Between the start and end of a bracketed paste
+	paste   strings.Builder // Text of the paste in progress
+}
+
+// NewClipboard creates a clipboard for s and enables bracketed paste
+func NewClipboard(s tcell.Screen) *Clipboard {
+	s.EnablePaste()
+	return &Clipboard{OSC52: DetectOSC52()}
+}
+
+// DetectOSC52 guesses from the environment whether the terminal accepts OSC 52
+// clipboard writes. The Linux console and dumb terminals do not.
+func DetectOSC52() bool {
+	switch os.Getenv("TERM") {
+	case "", "dumb", "linux", "cons25":
+		return false
+	}
+	return true
+}
+
+// Copy puts text on the clipboard
+func (c *Clipboard) Copy(s tcell.Screen, text string) {
+	c.mu.Lock()
+	c.text = text
+	c.mu.Unlock()
+
+	if c.OSC52 {
+		s.SetClipboard([]byte(text))
+	}
+}
+
+// Text returns the in-process clipboard text
+func (c *Clipboard) Text() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text
+}
+
+// Paste delivers the in-process clipboard text to the target. Text copied
+// outside the application arrives through the terminal's own paste command
+// as a bracketed paste instead.
+func (c *Clipboard) Paste() {
+	if text := c.Text(); text != "" && c.Target != nil {
+		c.Target.InsertText(text)
+	}
+}
+
+// Request asks the terminal for its clipboard contents. Terminals that allow
+// it answer with a *tcell.EventClipboard, which HandleEvent delivers to the
+// target; most ignore the request for security reasons.
+func (c *Clipboard) Request(s tcell.Screen) {
+	if c.OSC52 {
+		s.GetClipboard()
+	}
+}
+
+// HandleEvent collects bracketed pastes and clipboard replies and delivers
+// them to the target. It returns true if the event was consumed.
+func (c *Clipboard) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventPaste:
+		if e.Start() {
+			c.pasting = true
+			c.paste.Reset()
+			return true
+		}
+		c.pasting = false
+		if c.Target != nil && c.paste.Len() > 0 {
+			c.Target.InsertText(c.paste.String())
+		}
+		return true
+
+	case *tcell.EventKey:
+		if !c.pasting {
+			return false
+		}
+		switch e.Key() {
+		case tcell.KeyRune:
+			c.paste.WriteRune(e.Rune())
+		case tcell.KeyEnter, tcell.KeyLF:
+			c.paste.WriteByte('\n')
+		case tcell.KeyTab:
+			c.paste.WriteByte('\t')
+		}
+		return true
+
+	case *tcell.EventClipboard:
+		text := string(e.Data())
+		c.mu.Lock()
+		c.text = text
+		c.mu.Unlock()
+		if c.Target != nil {
+			c.Target.InsertText(text)
+		}
+		return true
+	}
+	return false
+}
+
+// InsertText implements PasteTarget. An editable box inserts the first line
+// of text at the cursor; other boxes select the item matching the text.
+func (c *ComboBox) InsertText(text string) {
+	text, _, _ = strings.Cut(text, "\n")
+	text = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	if !c.Editable {
+		for i, item := range c.Items {
+			if strings.EqualFold(item, strings.TrimSpace(text)) {
+				c.SetSelected(i)
+				c.notify()
+				return
+			}
+		}
+		return
+	}
+
+	runes := []rune(c.Text)
+	c.cursor = min(c.cursor, len(runes))
+	inserted := []rune(text)
+	c.Text = string(runes[:c.cursor]) + text + string(runes[c.cursor:])
+	c.cursor += len(inserted)
+	c.Selected = -1
+	if c.Open {
+		c.applyFilter(c.Text)
+		c.highlight = 0
+		c.scroll = 0
+	}
+}
@@ -0,0 +1,259 @@
+package retrotui
+
+import (
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ColorDepth is the number of colors a terminal can show
+type ColorDepth int
+
+const (
+	DepthMono      ColorDepth = 2
+	Depth8         ColorDepth = 8
+	Depth16        ColorDepth = 16
+	Depth256       ColorDepth = 256
+	DepthTrueColor ColorDepth = 1 << 24
+)
+
+// DetectColorDepth returns the color depth of the terminal behind s. Setting
+// the NO_COLOR environment variable selects monochrome.
+func DetectColorDepth(s tcell.Screen) ColorDepth {
+	if os.Getenv("NO_COLOR") != "" {
+		return DepthMono
+	}
+	switch n := s.Colors(); {
+	case n > 256:
+		return DepthTrueColor
+	case n > 16:
+		return Depth256
+	case n > 8:
+		return Depth16
+	case n > 2:
+		return Depth8
+	}
+	return DepthMono
+}
+
+// palettes holds the palettes of the limited color depths
+var palettes = func() map[ColorDepth][]tcell.Color {
+	m := make(map[ColorDepth][]tcell.Color)
+	for _, depth := range []ColorDepth{Depth8, Depth16, Depth256} {
+		for i := 0; i < int(depth); i++ {
+			m[depth] = append(m[depth], tcell.PaletteColor(i))
+		}
+	}
+	return m
+}()
+
+// AdaptColor returns the color nearest to c that a terminal of the given depth
+// can show. In monochrome that is black or white; see AdaptStyle.
+func AdaptColor(c tcell.Color, depth ColorDepth) tcell.Color {
+	if c == tcell.ColorDefault || c == tcell.ColorReset || !c.Valid() || depth >= DepthTrueColor {
+		return c
+	}
+	if depth <= DepthMono {
+		if luminance(c, 0) >= 0.5 {
+			return tcell.ColorWhite
+		}
+		return tcell.ColorBlack
+	}
+	if !c.IsRGB() && int(c-tcell.ColorValid) < int(depth) {
+		return c
+	}
+	palette, ok := palettes[depth]
+	if !ok {
+		palette = palettes[Depth8]
+	}
+	return tcell.FindColor(c, palette)
+}
+
+// AdaptStyle returns style with its colors adapted to the depth. Without color,
+// text on a lighter background is shown in reverse video and colored text in
+// bold, so highlights stay visible; underlines are kept.
+func AdaptStyle(style tcell.Style, depth ColorDepth) tcell.Style {
+	fg, bg, attr := style.Decompose()
+	if depth > DepthMono {
+		return style.Foreground(AdaptColor(fg, depth)).Background(AdaptColor(bg, depth))
+	}
+
+	reverse := luminance(bg, 0) > luminance(fg, 0.75)
+	ink := fg
+	if reverse {
+		ink = bg
+	}
+	return style.Foreground(tcell.ColorDefault).Background(tcell.ColorDefault).
+		Reverse((attr&tcell.AttrReverse != 0) != reverse).
+		Bold(attr&tcell.AttrBold != 0 || colorful(ink))
+}
+
+// luminance returns the brightness of c from 0 to 1, or def for the default color
+func luminance(c tcell.Color, def float64) float64 {
+	r, g, b := c.RGB()
+	if r < 0 {
+		return def
+	}
+	return (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 255
+}
+
+// colorful reports whether c is a saturated color rather than a gray
+func colorful(c tcell.Color) bool {
+	r, g, b := c.RGB()
+	if r < 0 {
+		return false
+	}
+	return max(r, g, b)-min(r, g, b) > 96
+}
+
+// LimitColors returns a screen that draws on s with every style adapted to
+// the given depth, for terminals that show fewer colors than they report or
+// to preview a design in fewer colors. It replaces any limit already applied
+// to s and keeps a palette forced by ForceCGA; at true color without such a
+// palette s itself is returned.
+func LimitColors(s tcell.Screen, depth ColorDepth) tcell.Screen {
+	styled := styleScreen{Screen: s}
+	if prev, ok := s.(*styleScreen); ok {
+		styled = *prev
+	}
+	styled.depth = depth
+	if depth >= DepthTrueColor && !styled.cga {
+		return styled.Screen
+	}
+	return &styled
+}
+
+// styleScreen adapts every style drawn on a screen: first to the text-mode
+// palette when cga is set, then to the color depth
+type styleScreen struct {
+	tcell.Screen
+	depth ColorDepth
+	cga   bool
+	blink BlinkMode
+}
+
+// adapt returns the style as drawn on the underlying screen
+func (s *styleScreen) adapt(style tcell.Style) tcell.Style {
+	if s.cga {
+		style = CGAStyle(style, s.blink)
+		if s.depth < DepthTrueColor || s.Screen.Colors() < int(DepthTrueColor) {
+			style = cgaToANSI(style)
+		}
+	}
+	if s.depth < DepthTrueColor {
+		style = AdaptStyle(style, s.depth)
+	}
+	return style
+}
+
+func (s *styleScreen) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.Screen.SetContent(x, y, mainc, combc, s.adapt(style))
+}
+
+func (s *styleScreen) Fill(r rune, style tcell.Style) {
+	s.Screen.Fill(r, s.adapt(style))
+}
+
+func (s *styleScreen) SetStyle(style tcell.Style) {
+	s.Screen.SetStyle(s.adapt(style))
+}
+
+func (s *styleScreen) Colors() int {
+	n := min(s.Screen.Colors(), int(s.depth))
+	if s.cga {
+		n = min(n, len(CGAPalette))
+	}
+	return n
+}
+
+// Theme is a named set of colors for the desktop, menus and windows. Designs
+// that do not survive the automatic mapping to fewer colors can provide hand
+// picked versions in Fallbacks.
+type Theme struct {
+	Name       string
+	Background tcell.Color
+	Menu       MenuBarColors
+	Window     UIColors
+	Fallbacks  map[ColorDepth]Theme // Versions for terminals with at most this many colors
+}
+
+// DefaultTheme returns the classic blue theme, with designed fallbacks for
+// 16 colors and monochrome
+func DefaultTheme() Theme {
+	t := Theme{
+		Name:       "classic",
+		Background: tcell.NewRGBColor(65, 70, 217),
+		Menu:       DefaultMenuBarColors(),
+		Window:     DefaultUIColors(),
+	}
+
+	low := t
+	low.Background = tcell.ColorNavy
+	low.Menu.BarBg = tcell.ColorTeal
+	low.Menu.BarFg = tcell.ColorBlack
+	low.Window.ActiveBg = tcell.ColorNavy
+	low.Window.StatusBg = tcell.ColorTeal
+	low.Window.StatusFg = tcell.ColorBlack
+
+	mono := t
+	mono.Background = tcell.ColorBlack
+	mono.Menu = MenuBarColors{
+		BarFg: tcell.ColorBlack, BarBg: tcell.ColorWhite,
+		ActiveFg: tcell.ColorWhite, ActiveBg: tcell.ColorBlack,
+		DropdownFg: tcell.ColorWhite, DropdownBg: tcell.ColorBlack,
+		DropdownActiveFg: tcell.ColorBlack, DropdownActiveBg: tcell.ColorWhite,
+		SeparatorColor: tcell.ColorWhite,
+	}
+	mono.Window = UIColors{
+		BorderFg: tcell.ColorWhite, BorderBg: tcell.ColorBlack,
+		TitleFg: tcell.ColorWhite, TitleBg: tcell.ColorBlack,
+		ControlFg: tcell.ColorWhite, ControlBg: tcell.ColorBlack,
+		TextFg:  tcell.ColorWhite,
+		FieldFg: tcell.ColorBlack, FieldBg: tcell.ColorWhite,
+		ListFg: tcell.ColorWhite, ListBg: tcell.ColorBlack,
+		ActiveFg: tcell.ColorBlack, ActiveBg: tcell.ColorWhite,
+		StatusFg: tcell.ColorBlack, StatusBg: tcell.ColorWhite,
+	}
+
+	t.Fallbacks = map[ColorDepth]Theme{Depth16: low, DepthMono: mono}
+	return t
+}
+
+// For returns the theme for a terminal of the given depth: the designed
+// fallback for the smallest depth that covers it, if any, with every color
+// mapped to the nearest one available. Monochrome colors are left for
+// AdaptStyle, which needs them to pick reverse video and bold.
+func (t Theme) For(depth ColorDepth) Theme {
+	best := ColorDepth(0)
+	for d := range t.Fallbacks {
+		if d >= depth && (best == 0 || d < best) {
+			best = d
+		}
+	}
+	if best != 0 {
+		t = t.Fallbacks[best]
+	}
+	t.Fallbacks = nil
+	if depth <= DepthMono || depth >= DepthTrueColor {
+		return t
+	}
+
+	return t.mapColors(func(c tcell.Color) tcell.Color { return AdaptColor(c, depth) })
+}
+
+// mapColors returns the theme with every color passed through f
+func (t Theme) mapColors(f func(tcell.Color) tcell.Color) Theme {
+	t.Background = f(t.Background)
+	for _, c := range []*tcell.Color{&t.Menu.BarFg, &t.Menu.BarBg, &t.Menu.ActiveFg, &t.Menu.ActiveBg,
+		&t.Menu.DropdownFg, &t.Menu.DropdownBg, &t.Menu.DropdownActiveFg, &t.Menu.DropdownActiveBg,
+		&t.Menu.SeparatorColor} {
+		*c = f(*c)
+	}
+	w := &t.Window
+	for _, c := range []*tcell.Color{&w.BorderFg, &w.BorderBg, &w.TitleFg, &w.TitleBg, &w.ControlFg,
+		&w.ControlBg, &w.TextFg, &w.FieldFg, &w.FieldBg, &w.ListFg, &w.ListBg, &w.ActiveFg, &w.ActiveBg,
+		&w.StatusFg, &w.StatusBg} {
+		*c = f(*c)
+	}
+	return t
+}
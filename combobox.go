@@ -0,0 +1,523 @@
+package retrotui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ComboBox represents a single-line field with a drop-down list of choices
+type ComboBox struct {
+	X          int
+	Y          int
+	Width      int
+	Items      []string
+	Text       string // Current text of the field
+	Selected   int    // Index of the chosen item, -1 when the text is free-form
+	Editable   bool   // true allows free-form entry, false restricts to Items
+	MaxVisible int    // Maximum number of rows shown in the popup list
+	Open       bool
+	Focused    bool
+	OnChange   func(text string, index int) // Called when the value is committed
+	Keymap     *Keymap                      // Navigation keys of a non-editable box; scope WidgetScope("list")
+
+	cursor    int   // Cursor position within Text, in runes
+	highlight int   // Highlighted row within the filtered list
+	picked    bool  // The highlight was moved since the text was last edited
+	scroll    int   // First visible row of the filtered list
+	filtered  []int // Indices into Items matching the current filter
+	savedText string
+	savedSel  int
+
+	// Popup geometry from the last draw, used for mouse hit testing
+	popupX, popupY, popupW, popupH int
+}
+
+// NewComboBox creates a new combo box with default values
+func NewComboBox(x, y, width int, items []string) *ComboBox {
+	c := &ComboBox{
+		X:          x,
+		Y:          y,
+		Width:      width,
+		Items:      items,
+		Selected:   -1,
+		MaxVisible: 8,
+	}
+	c.applyFilter("")
+	return c
+}
+
+// SetSelected selects the item at index and updates the field text
+func (c *ComboBox) SetSelected(index int) {
+	if index < 0 || index >= len(c.Items) {
+		return
+	}
+	c.Selected = index
+	c.Text = c.Items[index]
+	c.cursor = len([]rune(c.Text))
+}
+
+// Focus gives keyboard focus to the combo box
+func (c *ComboBox) Focus() {
+	c.Focused = true
+}
+
+// Blur removes keyboard focus and closes the popup, keeping the current text
+func (c *ComboBox) Blur() {
+	if c.Open && c.Editable {
+		c.Open = false
+		c.commitText()
+	} else {
+		c.CloseList()
+	}
+	c.Focused = false
+	c.Open = false
+}
+
+// OpenList opens the popup list, highlighting the current selection
+func (c *ComboBox) OpenList() {
+	if c.Open {
+		return
+	}
+	c.Open = true
+	c.Focused = true
+	c.savedText = c.Text
+	c.savedSel = c.Selected
+	c.applyFilter("")
+	c.highlight = 0
+	c.picked = c.Selected >= 0
+	for i, idx := range c.filtered {
+		if idx == c.Selected {
+			c.highlight = i
+			break
+		}
+	}
+	c.ensureVisible()
+}
+
+// CloseList closes the popup list, restoring the value it had when opened
+func (c *ComboBox) CloseList() {
+	if !c.Open {
+		return
+	}
+	c.Open = false
+	c.Text = c.savedText
+	c.Selected = c.savedSel
+	c.cursor = len([]rune(c.Text))
+}
+
+// Draw renders the combo box field and, when open, its popup list
+func (c *ComboBox) Draw(s tcell.Screen, fieldFg, fieldBg, listFg, listBg, activeFg, activeBg tcell.Color) {
+	if c.Width < 3 {
+		return
+	}
+
+	fieldStyle := tcell.StyleDefault.Foreground(fieldFg).Background(fieldBg)
+	if c.Focused && !c.Open {
+		fieldStyle = tcell.StyleDefault.Foreground(activeFg).Background(activeBg)
+	}
+
+	// Field text, scrolled so the cursor stays visible
+	textWidth := c.Width - 1
+	text := []rune(c.Text)
+	offset := 0
+	if c.Editable && c.cursor >= textWidth {
+		offset = c.cursor - textWidth + 1
+	}
+	for i := 0; i < textWidth; i++ {
+		r := ' '
+		if offset+i < len(text) {
+			r = text[offset+i]
+		}
+		s.SetContent(c.X+i, c.Y, r, nil, fieldStyle)
+	}
+
+	// Drop-down button
+	buttonStyle := tcell.StyleDefault.Foreground(fieldBg).Background(fieldFg)
+	s.SetContent(c.X+c.Width-1, c.Y, '▼', nil, buttonStyle)
+
+	// Editable fields show the cursor as a reversed cell
+	if c.Focused && c.Editable && c.cursor-offset < textWidth {
+		r, _, _, _ := s.GetContent(c.X+c.cursor-offset, c.Y)
+		s.SetContent(c.X+c.cursor-offset, c.Y, r, nil, fieldStyle.Reverse(true))
+	}
+
+	if !c.Open {
+		return
+	}
+
+	// Popup list below the field, or above it if there is no room
+	rows := len(c.filtered)
+	if rows > c.MaxVisible && c.MaxVisible > 0 {
+		rows = c.MaxVisible
+	}
+	if rows == 0 {
+		rows = 1
+	}
+	_, height := s.Size()
+	c.popupW = c.Width
+	c.popupH = rows + 2
+	c.popupX = c.X
+	c.popupY = c.Y + 1
+	if c.popupY+c.popupH > height && c.Y-c.popupH >= 0 {
+		c.popupY = c.Y - c.popupH
+	}
+
+	listOptions := DrawOptions{
+		FillPatternEnabled: false,
+		FillRune:           ' ',
+		ShadowEnabled:      true,
+		DoubleLine:         false,
+	}
+	DrawBox(s, c.popupX, c.popupY, c.popupW, c.popupH, listFg, listBg, listOptions)
+
+	itemWidth := c.popupW - 2
+	for row := 0; row < rows; row++ {
+		pos := c.scroll + row
+		if pos >= len(c.filtered) {
+			break
+		}
+		itemStyle := tcell.StyleDefault.Foreground(listFg).Background(listBg)
+		if pos == c.highlight {
+			itemStyle = tcell.StyleDefault.Foreground(activeFg).Background(activeBg)
+		}
+		label := []rune(c.Items[c.filtered[pos]])
+		for i := 0; i < itemWidth; i++ {
+			r := ' '
+			if i < len(label) {
+				r = label[i]
+			}
+			s.SetContent(c.popupX+1+i, c.popupY+1+row, r, nil, itemStyle)
+		}
+	}
+
+	// Scroll indicators on the right border
+	if c.scroll > 0 {
+		s.SetContent(c.popupX+c.popupW-1, c.popupY+1, '▲', nil, tcell.StyleDefault.Foreground(listFg).Background(listBg))
+	}
+	if c.scroll+rows < len(c.filtered) {
+		s.SetContent(c.popupX+c.popupW-1, c.popupY+rows, '▼', nil, tcell.StyleDefault.Foreground(listFg).Background(listBg))
+	}
+}
+
+// HandleEvent processes keyboard and mouse events for the combo box.
+// It returns true if the event was consumed and the combo box should be redrawn.
+func (c *ComboBox) HandleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		if !c.Focused {
+			return false
+		}
+		if !c.Editable {
+			// Editable boxes need every letter for typing
+			var consumed bool
+			if e, consumed = c.Keymap.translate(e, WidgetScope("list")); consumed {
+				return true
+			}
+		}
+		if c.Open {
+			return c.handleOpenKey(e)
+		}
+		return c.handleClosedKey(e)
+
+	case *tcell.EventMouse:
+		mouseX, mouseY := e.Position()
+		buttons := e.Buttons()
+
+		if c.Open && c.inPopup(mouseX, mouseY) {
+			row := mouseY - c.popupY - 1
+			pos := c.scroll + row
+			if row >= 0 && row < c.popupH-2 && pos < len(c.filtered) {
+				c.highlight = pos
+				c.picked = true
+				if buttons == tcell.ButtonPrimary {
+					c.choose(pos)
+				}
+			}
+			return true
+		}
+
+		if buttons != tcell.ButtonPrimary {
+			return false
+		}
+
+		if mouseY == c.Y && mouseX >= c.X && mouseX < c.X+c.Width {
+			c.Focused = true
+			if mouseX == c.X+c.Width-1 || !c.Editable {
+				if c.Open {
+					c.CloseList()
+				} else {
+					c.OpenList()
+				}
+			} else {
+				c.cursor = min(mouseX-c.X, len([]rune(c.Text)))
+			}
+			return true
+		}
+
+		// Clicking anywhere else takes the focus away
+		if c.Focused {
+			c.Blur()
+		}
+	}
+
+	return false
+}
+
+// handleClosedKey processes keys while the popup list is closed
+func (c *ComboBox) handleClosedKey(e *tcell.EventKey) bool {
+	switch e.Key() {
+	case tcell.KeyDown, tcell.KeyF4:
+		c.OpenList()
+		return true
+	case tcell.KeyUp:
+		if !c.Editable && c.Selected > 0 {
+			c.SetSelected(c.Selected - 1)
+			c.notify()
+			return true
+		}
+		return false
+	case tcell.KeyEnter:
+		if c.Editable {
+			c.commitText()
+			return true
+		}
+		return false
+	}
+
+	if c.Editable {
+		return c.editText(e)
+	}
+
+	// Type-ahead: jump to the next item starting with the typed letter
+	if e.Key() == tcell.KeyRune && e.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0 {
+		if idx := c.findPrefix(string(e.Rune()), c.Selected+1); idx >= 0 {
+			c.SetSelected(idx)
+			c.notify()
+		}
+		return true
+	}
+	return false
+}
+
+// handleOpenKey processes keys while the popup list is open
+func (c *ComboBox) handleOpenKey(e *tcell.EventKey) bool {
+	switch e.Key() {
+	case tcell.KeyEscape:
+		c.CloseList()
+		return true
+	case tcell.KeyTab, tcell.KeyBacktab:
+		// Let the caller move focus; the list closes with the focus loss
+		c.Blur()
+		return false
+	case tcell.KeyEnter:
+		// Typed text is committed as typed unless an item was picked since
+		if (c.picked || !c.Editable) && c.highlight >= 0 && c.highlight < len(c.filtered) {
+			c.choose(c.highlight)
+		} else {
+			c.Open = false
+			c.commitText()
+		}
+		return true
+	case tcell.KeyUp:
+		c.moveHighlight(-1)
+		return true
+	case tcell.KeyDown:
+		c.moveHighlight(1)
+		return true
+	case tcell.KeyPgUp:
+		c.moveHighlight(-c.visibleRows())
+		return true
+	case tcell.KeyPgDn:
+		c.moveHighlight(c.visibleRows())
+		return true
+	case tcell.KeyHome:
+		if !c.Editable {
+			c.highlight = 0
+			c.picked = true
+			c.ensureVisible()
+			return true
+		}
+	case tcell.KeyEnd:
+		if !c.Editable {
+			c.highlight = len(c.filtered) - 1
+			c.picked = true
+			c.ensureVisible()
+			return true
+		}
+	}
+
+	if c.Editable {
+		if c.editText(e) {
+			c.applyFilter(c.Text)
+			c.highlight = 0
+			c.scroll = 0
+			return true
+		}
+		return false
+	}
+
+	if e.Key() == tcell.KeyRune && e.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0 {
+		start := 0
+		if c.highlight >= 0 && c.highlight < len(c.filtered) {
+			start = c.filtered[c.highlight] + 1
+		}
+		if idx := c.findPrefix(string(e.Rune()), start); idx >= 0 {
+			for pos, item := range c.filtered {
+				if item == idx {
+					c.highlight = pos
+					c.ensureVisible()
+					break
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// editText applies a text editing key to the field
+func (c *ComboBox) editText(e *tcell.EventKey) bool {
+	text := []rune(c.Text)
+	switch e.Key() {
+	case tcell.KeyLeft:
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case tcell.KeyRight:
+		if c.cursor < len(text) {
+			c.cursor++
+		}
+	case tcell.KeyHome:
+		c.cursor = 0
+	case tcell.KeyEnd:
+		c.cursor = len(text)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if c.cursor == 0 {
+			return true
+		}
+		text = append(text[:c.cursor-1], text[c.cursor:]...)
+		c.cursor--
+		c.Text = string(text)
+		c.Selected = -1
+		c.picked = false
+	case tcell.KeyDelete:
+		if c.cursor >= len(text) {
+			return true
+		}
+		text = append(text[:c.cursor], text[c.cursor+1:]...)
+		c.Text = string(text)
+		c.Selected = -1
+		c.picked = false
+	case tcell.KeyRune:
+		if e.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 || !unicode.IsPrint(e.Rune()) {
+			return false
+		}
+		if !c.Open {
+			// Typing opens the list, which is then filtered by the text so far
+			c.OpenList()
+		}
+		text = append(text[:c.cursor], append([]rune{e.Rune()}, text[c.cursor:]...)...)
+		c.cursor++
+		c.Text = string(text)
+		c.Selected = -1
+		c.picked = false
+		c.applyFilter(c.Text)
+		c.highlight = 0
+		c.scroll = 0
+	default:
+		return false
+	}
+	return true
+}
+
+// choose commits the item at the given filtered position and closes the popup
+func (c *ComboBox) choose(pos int) {
+	c.SetSelected(c.filtered[pos])
+	c.Open = false
+	c.notify()
+}
+
+// commitText commits free-form text, matching it to an item when possible
+func (c *ComboBox) commitText() {
+	c.Selected = -1
+	for i, item := range c.Items {
+		if strings.EqualFold(item, c.Text) {
+			c.Selected = i
+			break
+		}
+	}
+	if !c.Editable && c.Selected < 0 {
+		c.Text = c.savedText
+		c.Selected = c.savedSel
+		return
+	}
+	c.notify()
+}
+
+// notify calls the OnChange callback if one is set
+func (c *ComboBox) notify() {
+	if c.OnChange != nil {
+		c.OnChange(c.Text, c.Selected)
+	}
+}
+
+// applyFilter rebuilds the filtered list from items containing the filter text
+func (c *ComboBox) applyFilter(filter string) {
+	c.filtered = c.filtered[:0]
+	filter = strings.ToLower(filter)
+	for i, item := range c.Items {
+		if filter == "" || strings.Contains(strings.ToLower(item), filter) {
+			c.filtered = append(c.filtered, i)
+		}
+	}
+}
+
+// findPrefix returns the first item at or after start (wrapping) that begins with prefix
+func (c *ComboBox) findPrefix(prefix string, start int) int {
+	n := len(c.Items)
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		if strings.HasPrefix(strings.ToLower(c.Items[idx]), strings.ToLower(prefix)) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// moveHighlight moves the highlighted row by delta, clamped to the list
+func (c *ComboBox) moveHighlight(delta int) {
+	if len(c.filtered) == 0 {
+		return
+	}
+	c.highlight = max(0, min(c.highlight+delta, len(c.filtered)-1))
+	c.picked = true
+	c.ensureVisible()
+}
+
+// ensureVisible scrolls the list so the highlighted row is visible
+func (c *ComboBox) ensureVisible() {
+	rows := c.visibleRows()
+	if c.highlight < c.scroll {
+		c.scroll = c.highlight
+	} else if c.highlight >= c.scroll+rows {
+		c.scroll = c.highlight - rows + 1
+	}
+	if c.scroll < 0 {
+		c.scroll = 0
+	}
+}
+
+// visibleRows returns the number of rows the popup list can show
+func (c *ComboBox) visibleRows() int {
+	if c.MaxVisible > 0 {
+		return c.MaxVisible
+	}
+	return len(c.filtered)
+}
+
+// inPopup reports whether (x, y) lies within the popup list
+func (c *ComboBox) inPopup(x, y int) bool {
+	return x >= c.popupX && x < c.popupX+c.popupW && y >= c.popupY && y < c.popupY+c.popupH
+}
@@ -0,0 +1,322 @@
+package retrotui
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Layer is an off-screen back buffer composited by a Compositor, usually one
+// per window. A layer is only redrawn when it is invalidated, so windows that
+// did not change are not drawn again.
+type Layer struct {
+	Draw  func(s tcell.Screen) // Draws the layer in screen coordinates; cells it leaves alone are transparent
+	Stamp func() any           // Optional comparable snapshot of what the layer shows; the layer is redrawn when it changes
+
+	comp    *Compositor
+	canvas  *Canvas
+	dirty   bool
+	stamp   any
+	stamped bool
+}
+
+// Invalidate marks the layer for redrawing by the next Render
+func (l *Layer) Invalidate() {
+	l.dirty = true
+}
+
+// Bounds returns the bounding box of the cells drawn by the last redraw
+func (l *Layer) Bounds() Rect {
+	return l.canvas.Bounds()
+}
+
+// CompositorStats counts the work done by a Compositor
+type CompositorStats struct {
+	Frames        int // Renders that pushed at least one cell
+	LayersDrawn   int // Layer redraws
+	CellsCompared int // Damaged cells compared with the previous frame
+	CellsWritten  int // Cells pushed to the terminal screen
+	LastFrame     int // Cells pushed by the last render
+}
+
+// Compositor keeps a back buffer per layer and only pushes the cells that
+// changed since the previous frame to the terminal. Layers are stacked bottom
+// first; redrawing, moving or restacking a layer damages the cells it covered
+// and now covers, and only damaged cells are recomposed and compared.
+//
+// Everything on screen must be drawn through layers: cells drawn directly on
+// the screen are not known to the compositor. Call Sync after doing so.
+type Compositor struct {
+	Screen tcell.Screen
+
+	stack     []*Layer
+	windows   map[*Window]*Layer
+	front     []cell // What the terminal shows
+	damaged   []bool
+	damage    Rect // Bounding box of the damaged cells
+	restacked Rect // Bounding box of the layers restacked since the last render
+	width     int
+	height    int
+	stats     CompositorStats
+}
+
+// NewCompositor creates a compositor drawing on s
+func NewCompositor(s tcell.Screen) *Compositor {
+	return &Compositor{Screen: s, windows: make(map[*Window]*Layer)}
+}
+
+// NewLayer creates a layer drawn by draw. Add it to the compositor with
+// SetStack.
+func (c *Compositor) NewLayer(draw func(s tcell.Screen)) *Layer {
+	return &Layer{Draw: draw, comp: c, canvas: NewCanvas(c.width, c.height), dirty: true}
+}
+
+// WindowLayer returns the layer drawing w with the given colors, creating it
+// on first use. The layer is redrawn when the window moves, resizes or changes
+// state; call Invalidate on it when the window's content changes.
+func (c *Compositor) WindowLayer(w *Window, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) *Layer {
+	if l, ok := c.windows[w]; ok {
+		return l
+	}
+	type windowStamp struct {
+		x, y, width, height int
+		title               string
+		border              BorderStyle
+		state               WindowState
+		scrollX, scrollY    int
+		visible, active     bool
+		menuOpen            bool
+	}
+	l := c.NewLayer(func(s tcell.Screen) {
+		w.Draw(s, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+	})
+	l.Stamp = func() any {
+		st := windowStamp{title: w.Title, border: w.Border, state: w.State, scrollX: w.ScrollX, scrollY: w.ScrollY,
+			visible: w.Visible, active: w.Active, menuOpen: w.ContextMenu != nil && w.ContextMenu.IsOpen()}
+		st.x, st.y, st.width, st.height = w.GetDimensions(c.Screen)
+		return st
+	}
+	c.windows[w] = l
+	return l
+}
+
+// WindowLayers returns the layers of windows in z-order, for use with SetStack
+func (c *Compositor) WindowLayers(windows []*Window, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) []*Layer {
+	layers := make([]*Layer, len(windows))
+	for i, w := range windows {
+		layers[i] = c.WindowLayer(w, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+	}
+	return layers
+}
+
+// SetStack sets the layers to composite, bottom first. Layers that moved in
+// the stack, or left it, damage the cells they covered.
+func (c *Compositor) SetStack(layers ...*Layer) {
+	c.resize()
+	for i, l := range c.stack {
+		if i >= len(layers) || layers[i] != l {
+			c.Damage(l.Bounds())
+			c.restacked = union(c.restacked, l.Bounds())
+		}
+	}
+	for i, l := range layers {
+		if i >= len(c.stack) || c.stack[i] != l {
+			c.Damage(l.Bounds())
+			c.restacked = union(c.restacked, l.Bounds())
+		}
+	}
+	c.stack = slices.Clone(layers)
+
+	// Forget the layers of windows that are gone
+	for w, l := range c.windows {
+		if !slices.Contains(c.stack, l) {
+			delete(c.windows, w)
+		}
+	}
+}
+
+// Invalidate marks every layer for redrawing
+func (c *Compositor) Invalidate() {
+	for _, l := range c.stack {
+		l.dirty = true
+	}
+}
+
+// Damage marks the cells of r for recomposing by the next Render
+func (c *Compositor) Damage(r Rect) {
+	x0, y0 := max(r.X, 0), max(r.Y, 0)
+	x1, y1 := min(r.X+r.Width, c.width), min(r.Y+r.Height, c.height)
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			c.damaged[y*c.width+x] = true
+		}
+	}
+	c.damage = union(c.damage, Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0})
+}
+
+// union returns the bounding box of two rectangles, ignoring empty ones
+func union(a, b Rect) Rect {
+	if a.Width <= 0 || a.Height <= 0 {
+		return b
+	}
+	if b.Width <= 0 || b.Height <= 0 {
+		return a
+	}
+	x0, y0 := min(a.X, b.X), min(a.Y, b.Y)
+	x1, y1 := max(a.X+a.Width, b.X+b.Width), max(a.Y+a.Height, b.Y+b.Height)
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// overlaps reports whether two rectangles share a cell
+func overlaps(a, b Rect) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
+
+// Sync forgets what the terminal shows, so the next Render pushes every cell,
+// and resynchronises the terminal screen
+func (c *Compositor) Sync() {
+	c.Screen.Sync()
+	clear(c.front)
+	c.Damage(Rect{Width: c.width, Height: c.height})
+}
+
+// resize reallocates the buffers when the screen size changed
+func (c *Compositor) resize() {
+	width, height := c.Screen.Size()
+	if width == c.width && height == c.height && c.front != nil {
+		return
+	}
+	c.width, c.height = width, height
+	c.front = make([]cell, width*height)
+	c.damaged = make([]bool, width*height)
+	c.damage = Rect{}
+	c.Damage(Rect{Width: width, Height: height})
+}
+
+// Render redraws the invalidated layers, recomposes the damaged cells and
+// pushes those that changed to the terminal. The screen is only shown when
+// something changed.
+func (c *Compositor) Render() {
+	c.resize()
+	changed := c.restacked
+	c.restacked = Rect{}
+	for i, l := range c.stack {
+		if w, h := l.canvas.Size(); w != c.width || h != c.height {
+			l.canvas = NewCanvas(c.width, c.height)
+			l.dirty = true
+		}
+		if l.Stamp != nil {
+			if st := l.Stamp(); !l.stamped || st != l.stamp {
+				l.stamp, l.stamped = st, true
+				l.dirty = true
+			}
+		}
+		// Transparent shadows show the layers below, so a layer over cells
+		// that changed below it is drawn again
+		if l.dirty || i > 0 && overlaps(l.Bounds(), changed) {
+			before := l.Bounds()
+			c.redraw(l)
+			changed = union(changed, union(before, l.Bounds()))
+		}
+	}
+
+	written := 0
+	d := c.damage
+	for y := d.Y; y < d.Y+d.Height; y++ {
+		for x := d.X; x < d.X+d.Width; x++ {
+			i := y*c.width + x
+			if !c.damaged[i] {
+				continue
+			}
+			c.damaged[i] = false
+			c.stats.CellsCompared++
+			top := c.compose(len(c.stack), x, y)
+			if c.front[i].mainc != 0 && c.front[i].equal(top) {
+				continue
+			}
+			c.front[i] = top
+			c.Screen.SetContent(x, y, top.mainc, top.combc, top.style)
+			written++
+		}
+	}
+	c.damage = Rect{}
+
+	c.stats.LastFrame = written
+	if written > 0 {
+		c.stats.Frames++
+		c.stats.CellsWritten += written
+		c.Screen.Show()
+	}
+}
+
+// redraw clears a layer and draws it again, damaging the cells it covered
+// before and after
+func (c *Compositor) redraw(l *Layer) {
+	c.Damage(l.Bounds())
+	l.canvas.Clear()
+	l.dirty = false
+	if l.Draw != nil {
+		// Reads see the layer over the layers below it, so shadows and the
+		// like work as usual
+		below := slices.Index(c.stack, l)
+		l.Draw(&canvasScreen{Screen: c.Screen, canvas: l.canvas, below: func(x, y int) cell {
+			return c.compose(below, x, y)
+		}})
+	}
+	c.Damage(l.Bounds())
+	c.stats.LayersDrawn++
+}
+
+// compose returns the cell at (x, y) seen through the layers below index top
+func (c *Compositor) compose(top, x, y int) cell {
+	for n := top - 1; n >= 0; n-- {
+		if cl, ok := c.stack[n].canvas.at(x, y); ok {
+			return cl
+		}
+	}
+	return cell{mainc: ' ', style: tcell.StyleDefault}
+}
+
+// Stats returns the work counters
+func (c *Compositor) Stats() CompositorStats {
+	return c.stats
+}
+
+// ResetStats zeroes the work counters
+func (c *Compositor) ResetStats() {
+	c.stats = CompositorStats{}
+}
+
+// ByteCounter wraps a terminal and counts the bytes written to it, to measure
+// how much output drawing produces
+type ByteCounter struct {
+	tcell.Tty
+	n atomic.Int64
+}
+
+// Write implements io.Writer
+func (b *ByteCounter) Write(p []byte) (int, error) {
+	n, err := b.Tty.Write(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// Bytes returns the number of bytes written so far
+func (b *ByteCounter) Bytes() int64 {
+	return b.n.Load()
+}
+
+// NewCountingScreen creates a screen on tty, e.g. from tcell.NewDevTty, that
+// counts the bytes it writes
+func NewCountingScreen(tty tcell.Tty) (tcell.Screen, *ByteCounter, error) {
+	counter := &ByteCounter{Tty: tty}
+	s, err := tcell.NewTerminfoScreenFromTty(counter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, counter, nil
+}
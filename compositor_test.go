@@ -0,0 +1,253 @@
+package retrotui
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// fakeTty is a terminal of a fixed size that discards output and never has
+// input
+type fakeTty struct {
+	width, height int
+	mu            sync.Mutex
+	drained       chan struct{} // Closed by Drain to wake up Read
+}
+
+func (t *fakeTty) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.drained = make(chan struct{})
+	return nil
+}
+
+func (t *fakeTty) Drain() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	close(t.drained)
+	return nil
+}
+
+func (t *fakeTty) Stop() error                 { return nil }
+func (t *fakeTty) NotifyResize(cb func())      {}
+func (t *fakeTty) Close() error                { return nil }
+func (t *fakeTty) Write(p []byte) (int, error) { return len(p), nil }
+
+func (t *fakeTty) Read(p []byte) (int, error) {
+	t.mu.Lock()
+	drained := t.drained
+	t.mu.Unlock()
+	<-drained
+	return 0, io.EOF
+}
+
+func (t *fakeTty) WindowSize() (tcell.WindowSize, error) {
+	return tcell.WindowSize{Width: t.width, Height: t.height}, nil
+}
+
+// countingScreen counts the cells drawn on a screen
+type countingScreen struct {
+	tcell.Screen
+	cells int
+}
+
+func (s *countingScreen) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.cells++
+	s.Screen.SetContent(x, y, mainc, combc, style)
+}
+
+// newCountingScreen returns an initialised terminal screen of the given size
+// that counts the cells drawn on it and the bytes it writes
+func newCountingScreen(t *testing.T, width, height int) (*countingScreen, *ByteCounter) {
+	t.Helper()
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORTERM", "")
+	s, counter, err := NewCountingScreen(&fakeTty{width: width, height: height})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Fini)
+	return &countingScreen{Screen: s}, counter
+}
+
+func newSimulationScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	t.Helper()
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Fini)
+	s.SetSize(width, height)
+	return s
+}
+
+// fillLayer returns a layer filling the rectangle r with ch
+func fillLayer(c *Compositor, r *Rect, ch *rune, style tcell.Style) *Layer {
+	return c.NewLayer(func(s tcell.Screen) {
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				s.SetContent(x, y, *ch, nil, style)
+			}
+		}
+	})
+}
+
+func TestCompositorWritesLess(t *testing.T) {
+	const width, height = 80, 25
+	windows := []*Window{
+		NewWindow("Back", 5, 3, 40, 12),
+		NewWindow("Middle", 20, 8, 40, 12),
+		NewWindow("Front", 2, 2, 30, 10),
+	}
+	for _, w := range windows {
+		w.Content = func(s tcell.Screen, x, y, width, height int) {
+			for row := 0; row < height; row++ {
+				PrintAt(s, x, y+row, "The quick brown fox jumps over the lazy dog", tcell.StyleDefault)
+			}
+		}
+	}
+	drawWindows := func(s tcell.Screen) {
+		DrawWindows(s, windows, tcell.ColorWhite, tcell.ColorBlue, tcell.ColorYellow, tcell.ColorBlue, tcell.ColorRed, tcell.ColorBlue)
+	}
+
+	// Drag the front window across the screen and back
+	drag := func(frame func()) {
+		front := windows[len(windows)-1]
+		for i := 0; i < 20; i++ {
+			front.X, front.Y = 2+i*2, 2+i/2
+			frame()
+		}
+		for i := 20; i >= 0; i-- {
+			front.X, front.Y = 2+i*2, 2+i/2
+			frame()
+		}
+	}
+
+	full, fullBytes := newCountingScreen(t, width, height)
+	drag(func() {
+		DrawBackground(full, tcell.ColorNavy, true, '░')
+		drawWindows(full)
+		full.Show()
+	})
+
+	comp, compBytes := newCountingScreen(t, width, height)
+	c := NewCompositor(comp)
+	background := c.NewLayer(func(s tcell.Screen) {
+		DrawBackground(s, tcell.ColorNavy, true, '░')
+	})
+	drag(func() {
+		c.SetStack(append([]*Layer{background}, c.WindowLayers(windows,
+			tcell.ColorWhite, tcell.ColorBlue, tcell.ColorYellow, tcell.ColorBlue, tcell.ColorRed, tcell.ColorBlue)...)...)
+		c.Render()
+	})
+
+	t.Logf("full repaint: %d cells, %d bytes; compositor: %d cells, %d bytes",
+		full.cells, fullBytes.Bytes(), comp.cells, compBytes.Bytes())
+	if comp.cells >= full.cells {
+		t.Errorf("compositor drew %d cells, full repaint %d", comp.cells, full.cells)
+	}
+	if compBytes.Bytes() >= fullBytes.Bytes() {
+		t.Errorf("compositor wrote %d bytes, full repaint %d", compBytes.Bytes(), fullBytes.Bytes())
+	}
+}
+
+func TestCompositorShadowFollowsLayerBelow(t *testing.T) {
+	s := newSimulationScreen(t, 20, 10)
+	c := NewCompositor(s)
+	under := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorTeal)
+	ch := 'a'
+	background := fillLayer(c, &Rect{Width: 20, Height: 10}, &ch, under)
+	box := c.NewLayer(func(s tcell.Screen) {
+		DrawBox(s, 2, 2, 6, 4, tcell.ColorWhite, tcell.ColorNavy, DrawOptions{ShadowEnabled: true, ShadowMode: ShadowTransparent})
+	})
+	c.SetStack(background, box)
+	c.Render()
+
+	// Only the layer below changes; the shadow over it must follow
+	ch = 'b'
+	background.Invalidate()
+	c.Render()
+	for _, p := range []struct{ x, y int }{{8, 3}, {8, 6}, {3, 6}} {
+		r, _, style, _ := s.GetContent(p.x, p.y)
+		if r != 'b' || style != DimStyle(under) {
+			t.Errorf("shadow at %d,%d is %q %v, want 'b' %v", p.x, p.y, r, style, DimStyle(under))
+		}
+	}
+}
+
+func TestCompositorSetStack(t *testing.T) {
+	s := newSimulationScreen(t, 20, 10)
+	c := NewCompositor(s)
+	chA, chB := 'A', 'B'
+	a := fillLayer(c, &Rect{X: 0, Y: 0, Width: 6, Height: 3}, &chA, tcell.StyleDefault)
+	b := fillLayer(c, &Rect{X: 3, Y: 1, Width: 6, Height: 3}, &chB, tcell.StyleDefault)
+	at := func(x, y int) rune {
+		r, _, _, _ := s.GetContent(x, y)
+		return r
+	}
+
+	c.SetStack(a, b)
+	c.Render()
+	if got := at(4, 1); got != 'B' {
+		t.Errorf("overlap shows %q with B on top", got)
+	}
+
+	c.SetStack(a, b)
+	c.Render()
+	if n := c.Stats().LastFrame; n != 0 {
+		t.Errorf("unchanged stack pushed %d cells", n)
+	}
+
+	c.SetStack(b, a)
+	c.Render()
+	if got := at(4, 1); got != 'A' {
+		t.Errorf("overlap shows %q with A on top", got)
+	}
+	// Only the overlap changes hands
+	if n := c.Stats().LastFrame; n != 6 {
+		t.Errorf("restacking pushed %d cells, want 6", n)
+	}
+
+	// A layer that leaves the stack uncovers what is below it
+	c.SetStack(b)
+	c.Render()
+	if got := at(1, 0); got != ' ' {
+		t.Errorf("cell of the removed layer shows %q", got)
+	}
+	if got := at(4, 1); got != 'B' {
+		t.Errorf("overlap shows %q after removing A", got)
+	}
+	if n := c.Stats().LastFrame; n != 6*3 {
+		t.Errorf("removing A pushed %d cells, want %d", n, 6*3)
+	}
+}
+
+func TestCompositorSync(t *testing.T) {
+	const width, height = 20, 10
+	s := newSimulationScreen(t, width, height)
+	c := NewCompositor(s)
+	ch := 'a'
+	c.SetStack(fillLayer(c, &Rect{Width: width, Height: height}, &ch, tcell.StyleDefault))
+	c.Render()
+
+	// Drawing behind the compositor's back goes unnoticed until Sync
+	s.SetContent(3, 4, 'X', nil, tcell.StyleDefault)
+	c.Render()
+	if r, _, _, _ := s.GetContent(3, 4); r != 'X' || c.Stats().LastFrame != 0 {
+		t.Fatalf("render without changes pushed %d cells", c.Stats().LastFrame)
+	}
+
+	c.Sync()
+	c.Render()
+	if r, _, _, _ := s.GetContent(3, 4); r != 'a' {
+		t.Errorf("cell drawn directly is %q after Sync, want 'a'", r)
+	}
+	if n := c.Stats().LastFrame; n != width*height {
+		t.Errorf("render after Sync pushed %d cells, want %d", n, width*height)
+	}
+}
@@ -0,0 +1,205 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// ContextMenu is a popup menu opened at the mouse position by a right click,
+// or from the keyboard with Shift+F10. It uses the same items, rendering and
+// navigation as the drop-down menus of a MenuBar.
+type ContextMenu struct {
+	Items  []DropdownItem
+	Colors MenuBarColors
+	OnOpen func(c *ContextMenu) // Called before the menu is shown, e.g. to rebuild Items
+	Keymap *Keymap              // Navigation keys; scope WidgetScope("menu")
+
+	x, y int
+	open bool
+	nav  menuNav
+}
+
+// NewContextMenu creates a closed context menu with the default menu colors
+func NewContextMenu(items []DropdownItem) *ContextMenu {
+	return &ContextMenu{
+		Items:  items,
+		Colors: DefaultMenuBarColors(),
+	}
+}
+
+// ContextMenuTrigger recognises the events that open a context menu. Like
+// MouseTracker it remembers the buttons held, so a menu opens when the button
+// goes down and not again while the mouse moves with it held.
+type ContextMenuTrigger struct {
+	buttons tcell.ButtonMask // Buttons held at the last mouse event
+}
+
+// Check reports whether ev should open a context menu: a press of the
+// secondary or middle mouse button, or Shift+F10, the keyboard equivalent of
+// the Menu key. For mouse events it also returns the pointer position,
+// otherwise -1, -1. Every mouse event should be checked, so that releases
+// are seen.
+func (t *ContextMenuTrigger) Check(ev tcell.Event) (x, y int, ok bool) {
+	switch e := ev.(type) {
+	case *tcell.EventMouse:
+		const menuButtons = tcell.Button2 | tcell.Button3
+		held := t.buttons
+		t.buttons = e.Buttons()
+		if e.Buttons()&menuButtons&^held != 0 {
+			x, y = e.Position()
+			return x, y, true
+		}
+	case *tcell.EventKey:
+		if e.Key() == tcell.KeyF10 && e.Modifiers()&tcell.ModShift != 0 {
+			return -1, -1, true
+		}
+	}
+	return -1, -1, false
+}
+
+// Show opens the menu with its top-left corner at (x, y). The menu is moved
+// as needed to stay on screen when it is drawn. OnOpen and the item callbacks
+// are evaluated first.
+func (c *ContextMenu) Show(x, y int) {
+	if c.OnOpen != nil {
+		c.OnOpen(c)
+	}
+	RefreshMenuItems(c.Items)
+	c.x, c.y = x, y
+	c.open = true
+	c.nav.reset(c.Items)
+}
+
+// Close closes the menu
+func (c *ContextMenu) Close() {
+	c.open = false
+	c.nav.path = nil
+}
+
+// IsOpen reports whether the menu is shown
+func (c *ContextMenu) IsOpen() bool {
+	return c.open
+}
+
+// bounds returns the menu box, moved to stay within the screen
+func (c *ContextMenu) bounds(s tcell.Screen) menuBox {
+	screenWidth, screenHeight := s.Size()
+	width := dropdownWidth(c.Items)
+	height := visibleCount(c.Items) + 2
+
+	// Open up or to the left of the pointer when there is no room
+	x, y := c.x, c.y
+	if x+width > screenWidth {
+		x = max(0, min(x-width+1, screenWidth-width))
+	}
+	if y+height > screenHeight {
+		y = max(0, min(y-height+1, screenHeight-height))
+	}
+	return menuBox{x: x, y: y, width: width, height: height, items: c.Items, active: c.nav.active}
+}
+
+// Draw renders the menu and its open submenus if the menu is open
+func (c *ContextMenu) Draw(s tcell.Screen) {
+	if !c.open {
+		return
+	}
+	col := c.Colors
+	for _, box := range cascadeFrom(s, c.bounds(s), c.nav.path) {
+		drawMenuBox(s, box, col.DropdownFg, col.DropdownBg, col.DropdownActiveFg, col.DropdownActiveBg, col.SeparatorColor)
+	}
+}
+
+// HandleEvent processes events while the menu is open. Any mouse press
+// outside the menu closes it. It returns true if the event was consumed and
+// the screen should be redrawn. A chosen item posts an EventMenuSelect with
+// Menu set to -1.
+func (c *ContextMenu) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	if !c.open {
+		return false
+	}
+
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		e, consumed := c.Keymap.translate(e, WidgetScope("menu"))
+		if consumed {
+			return true
+		}
+		action, path, item := c.nav.handleKey(s, c.Items, e, c.Close)
+		if action == menuActionClose {
+			c.Close()
+		}
+		postSelect(s, -1, path, item)
+		return true
+
+	case *tcell.EventMouse:
+		mouseX, mouseY := e.Position()
+		buttons := e.Buttons()
+		level, index, hit := hitMenuBoxes(cascadeFrom(s, c.bounds(s), c.nav.path), mouseX, mouseY)
+		if !hit {
+			if buttons != tcell.ButtonNone {
+				c.Close()
+				return true
+			}
+			return false
+		}
+		path, item := c.nav.handleMouse(s, c.Items, level, index, buttons, c.Close)
+		postSelect(s, -1, path, item)
+		return true
+
+	case *tcell.EventResize:
+		// The position is re-clamped on the next draw
+		return false
+	}
+	return false
+}
+
+// ContextMenuArea attaches a context menu to a widget. A right click inside
+// the widget, or Shift+F10 while it has focus, opens the menu.
+type ContextMenuArea struct {
+	Widget Widget
+	Menu   *ContextMenu
+
+	screen  tcell.Screen // Screen from the last draw, needed to activate items
+	area    Rect
+	trigger ContextMenuTrigger
+}
+
+// AttachContextMenu wraps w so that it opens menu on a right click
+func AttachContextMenu(w Widget, menu *ContextMenu) *ContextMenuArea {
+	return &ContextMenuArea{Widget: w, Menu: menu}
+}
+
+// Draw draws the widget and, if open, the context menu over it
+func (a *ContextMenuArea) Draw(s tcell.Screen, x, y, width, height int) {
+	a.screen = s
+	a.area = Rect{X: x, Y: y, Width: width, Height: height}
+	if a.Widget != nil {
+		a.Widget.Draw(s, x, y, width, height)
+	}
+	a.Menu.Draw(s)
+}
+
+// HandleEvent gives an open menu the first chance at events, opens the menu
+// on a context menu event and forwards everything else to the widget
+func (a *ContextMenuArea) HandleEvent(ev tcell.Event) bool {
+	x, y, ok := a.trigger.Check(ev)
+	if a.screen != nil && a.Menu.HandleEvent(a.screen, ev) {
+		return true
+	}
+
+	if ok {
+		if x < 0 {
+			// Opened from the keyboard: use the widget's top-left corner
+			a.Menu.Show(a.area.X+1, a.area.Y+1)
+			return true
+		}
+		if a.area.Contains(x, y) {
+			a.Menu.Show(x, y)
+			return true
+		}
+	}
+
+	if a.Widget != nil {
+		return a.Widget.HandleEvent(ev)
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package retrotui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestContextMenuTrigger(t *testing.T) {
+	mouse := func(x, y int, buttons tcell.ButtonMask) tcell.Event {
+		return tcell.NewEventMouse(x, y, buttons, tcell.ModNone)
+	}
+	steps := []struct {
+		ev   tcell.Event
+		want bool
+	}{
+		{mouse(1, 1, tcell.ButtonNone), false},
+		{mouse(1, 1, tcell.Button2), true},
+		{mouse(2, 1, tcell.Button2), false}, // Dragging with the button held
+		{mouse(3, 2, tcell.Button2), false},
+		{mouse(3, 2, tcell.ButtonNone), false},
+		{mouse(4, 2, tcell.Button1), false},
+		{mouse(4, 2, tcell.Button1|tcell.Button3), true},
+		{mouse(4, 2, tcell.ButtonNone), false},
+		{tcell.NewEventKey(tcell.KeyF10, 0, tcell.ModShift), true},
+		{tcell.NewEventKey(tcell.KeyF10, 0, tcell.ModNone), false},
+	}
+	var trigger ContextMenuTrigger
+	for i, step := range steps {
+		if _, _, ok := trigger.Check(step.ev); ok != step.want {
+			t.Errorf("step %d: Check = %v, want %v", i, ok, step.want)
+		}
+	}
+}
+
+func TestWindowContextMenuDrag(t *testing.T) {
+	s := newSimulationScreen(t, 40, 20)
+	back := NewWindow("Back", 0, 0, 30, 15)
+	front := NewWindow("Front", 10, 5, 30, 15)
+	back.ContextMenu = NewContextMenu([]DropdownItem{{Text: "One"}, {Text: "Two"}})
+	opened := 0
+	back.ContextMenu.OnOpen = func(c *ContextMenu) { opened++ }
+	windows := []*Window{back, front}
+
+	// A right press on the back window opens its menu and raises it
+	if !HandleWindows(s, windows, tcell.NewEventMouse(2, 2, tcell.Button2, tcell.ModNone)) {
+		t.Fatal("right press was not handled")
+	}
+	if !back.ContextMenu.IsOpen() || windows[1] != back {
+		t.Fatalf("menu open %v, top window %q", back.ContextMenu.IsOpen(), windows[1].Title)
+	}
+
+	// Dragging with the button held does not open the menu again under the
+	// pointer
+	for x := 3; x < 25; x++ {
+		HandleWindows(s, windows, tcell.NewEventMouse(x, 10, tcell.Button2, tcell.ModNone))
+	}
+	HandleWindows(s, windows, tcell.NewEventMouse(25, 10, tcell.ButtonNone, tcell.ModNone))
+	if opened != 1 {
+		t.Errorf("menu opened %d times, want once", opened)
+	}
+
+	// The next press opens it again
+	HandleWindows(s, windows, tcell.NewEventMouse(5, 5, tcell.Button2, tcell.ModNone))
+	if opened != 2 || !back.ContextMenu.IsOpen() {
+		t.Errorf("menu opened %d times after a second press, open %v", opened, back.ContextMenu.IsOpen())
+	}
+}
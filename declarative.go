@@ -0,0 +1,602 @@
+package retrotui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// UIDefinition describes a whole screen: the menu bar, windows, dialogs with
+// their forms and the status bar text. It is usually loaded from a JSON
+// document with LoadUI, with action names bound to Go handlers through an
+// ActionRegistry.
+type UIDefinition struct {
+	Background string             `json:"background,omitempty"` // Color name or "#rrggbb"
+	Status     string             `json:"status,omitempty"`     // Status bar text
+	Menus      []MenuDefinition   `json:"menus,omitempty"`
+	Windows    []WindowDefinition `json:"windows,omitempty"`
+	Dialogs    []WindowDefinition `json:"dialogs,omitempty"` // Centred windows that start hidden
+}
+
+// MenuDefinition describes a menu of the menu bar
+type MenuDefinition struct {
+	Title  string               `json:"title"`
+	HotKey string               `json:"hotkey,omitempty"` // Letter that opens the menu with Alt
+	Align  string               `json:"align,omitempty"`  // "left" (the default) or "right"
+	Items  []MenuItemDefinition `json:"items,omitempty"`
+}
+
+// MenuItemDefinition describes a menu item, a separator or a submenu
+type MenuItemDefinition struct {
+	Text       string               `json:"text,omitempty"`
+	ID         string               `json:"id,omitempty"`
+	Separator  bool                 `json:"separator,omitempty"`
+	Action     string               `json:"action,omitempty"` // Name of the handler in the ActionRegistry
+	Dialog     string               `json:"dialog,omitempty"` // ID of a dialog shown when the item is chosen
+	Shortcut   string               `json:"shortcut,omitempty"`
+	HotKey     string               `json:"hotkey,omitempty"`
+	Disabled   bool                 `json:"disabled,omitempty"`
+	Hidden     bool                 `json:"hidden,omitempty"`
+	Checkable  bool                 `json:"checkable,omitempty"`
+	Checked    bool                 `json:"checked,omitempty"`
+	RadioGroup string               `json:"radioGroup,omitempty"`
+	Items      []MenuItemDefinition `json:"items,omitempty"`
+}
+
+// WindowDefinition describes a window or dialog with optional text, form
+// fields and buttons, laid out from top to bottom in that order
+type WindowDefinition struct {
+	ID      string             `json:"id"`
+	Title   string             `json:"title,omitempty"`
+	X       int                `json:"x,omitempty"`
+	Y       int                `json:"y,omitempty"`
+	Width   int                `json:"width"`
+	Height  int                `json:"height"`
+	Center  bool               `json:"center,omitempty"` // Centre on the screen instead of using X and Y
+	Hidden  bool               `json:"hidden,omitempty"`
+	Border  string             `json:"border,omitempty"` // Name of a border style such as "rounded"; double by default
+	Text    []string           `json:"text,omitempty"`
+	Fields  []FieldDefinition  `json:"fields,omitempty"`
+	Buttons []ButtonDefinition `json:"buttons,omitempty"`
+}
+
+// FieldDefinition describes a labelled form field. Type is "text" for
+// free-form entry (the default), "combo" for free-form entry with a list of
+// suggestions or "list" to restrict the value to Items.
+type FieldDefinition struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label,omitempty"`
+	Type   string   `json:"type,omitempty"`
+	Items  []string `json:"items,omitempty"`
+	Value  string   `json:"value,omitempty"`
+	Width  int      `json:"width,omitempty"`  // Field width; defaults to the rest of the window
+	Action string   `json:"action,omitempty"` // Called when the value is committed
+}
+
+// ButtonDefinition describes a button at the bottom of a window. A button
+// without an action closes its window.
+type ButtonDefinition struct {
+	Text   string `json:"text"`
+	Action string `json:"action,omitempty"`
+}
+
+// ActionRegistry binds the action names used in a UI definition to Go handlers
+type ActionRegistry map[string]func(s tcell.Screen)
+
+// UIColors holds the colors of a UI built from a definition
+type UIColors struct {
+	BorderFg  tcell.Color
+	BorderBg  tcell.Color
+	TitleFg   tcell.Color
+	TitleBg   tcell.Color
+	ControlFg tcell.Color
+	ControlBg tcell.Color
+	TextFg    tcell.Color
+	FieldFg   tcell.Color
+	FieldBg   tcell.Color
+	ListFg    tcell.Color
+	ListBg    tcell.Color
+	ActiveFg  tcell.Color
+	ActiveBg  tcell.Color
+	StatusFg  tcell.Color
+	StatusBg  tcell.Color
+}
+
+// DefaultUIColors returns the classic blue window color scheme
+func DefaultUIColors() UIColors {
+	return UIColors{
+		BorderFg:  tcell.ColorWhite,
+		BorderBg:  tcell.ColorBlue,
+		TitleFg:   tcell.ColorYellow,
+		TitleBg:   tcell.ColorBlue,
+		ControlFg: tcell.ColorRed,
+		ControlBg: tcell.ColorBlue,
+		TextFg:    tcell.ColorWhite,
+		FieldFg:   tcell.ColorBlack,
+		FieldBg:   tcell.ColorTeal,
+		ListFg:    tcell.ColorBlack,
+		ListBg:    tcell.ColorLightGray,
+		ActiveFg:  tcell.ColorWhite,
+		ActiveBg:  tcell.ColorDarkBlue,
+		StatusFg:  tcell.ColorGreen,
+		StatusBg:  tcell.ColorDarkBlue,
+	}
+}
+
+// UI is a screen built from a UIDefinition
+type UI struct {
+	Background tcell.Color
+	Status     string
+	MenuBar    *MenuBar
+	Windows    []*Window // Windows and dialogs, bottom-most first
+	Colors     UIColors
+	Clipboard  *Clipboard // When set, pastes go to the focused field
+
+	screen  tcell.Screen // Screen from the last draw, passed to field actions
+	windows map[string]*Window
+	fields  map[string]*ComboBox
+	forms   map[*Window]*uiForm
+}
+
+// uiForm holds the contents of a window built from a definition
+type uiForm struct {
+	ui      *UI
+	window  *Window
+	text    []string
+	labels  []string
+	widths  []int
+	fields  []*ComboBox
+	buttons []uiButton
+	focus   int  // Focused field, -1 for none
+	center  bool // Centre the window before its next draw
+}
+
+// uiButton is a button of a form with its position from the last draw
+type uiButton struct {
+	text   string
+	action func(s tcell.Screen)
+	x, y   int
+	width  int // Cells taken by the button, brackets included
+}
+
+// LoadUIFile reads a JSON UI definition from path and builds it
+func LoadUIFile(path string, actions ActionRegistry) (*UI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadUI(f, actions)
+}
+
+// LoadUI reads a JSON UI definition from r and builds it. Unknown keys are
+// reported as errors so that typos do not go unnoticed.
+func LoadUI(r io.Reader, actions ActionRegistry) (*UI, error) {
+	var def UIDefinition
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("reading UI definition: %w", err)
+	}
+	return BuildUI(def, actions)
+}
+
+// BuildUI creates the menu bar, windows and dialogs of a definition. It
+// returns an error for unknown actions or dialogs, invalid shortcuts and
+// duplicate IDs.
+func BuildUI(def UIDefinition, actions ActionRegistry) (*UI, error) {
+	ui := &UI{
+		Background: tcell.ColorDarkBlue,
+		Status:     def.Status,
+		Colors:     DefaultUIColors(),
+		windows:    make(map[string]*Window),
+		fields:     make(map[string]*ComboBox),
+		forms:      make(map[*Window]*uiForm),
+	}
+	if def.Background != "" {
+		ui.Background = tcell.GetColor(def.Background)
+		if ui.Background == tcell.ColorDefault {
+			return nil, fmt.Errorf("unknown background color %q", def.Background)
+		}
+	}
+
+	for _, w := range def.Windows {
+		if err := ui.addWindow(w, false, actions); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range def.Dialogs {
+		if err := ui.addWindow(d, true, actions); err != nil {
+			return nil, err
+		}
+	}
+
+	// Menus go last so that items can refer to the dialogs
+	menus := make([]Menu, 0, len(def.Menus))
+	for _, md := range def.Menus {
+		items, err := ui.buildItems(md.Items, actions)
+		if err != nil {
+			return nil, fmt.Errorf("menu %q: %w", md.Title, err)
+		}
+		menus = append(menus, Menu{
+			Title:  md.Title,
+			HotKey: firstRune(md.HotKey),
+			Items:  items,
+			Align:  strings.EqualFold(md.Align, "right"),
+		})
+	}
+	bar, err := NewMenuBar(menus)
+	if err != nil {
+		return nil, err
+	}
+	ui.MenuBar = bar
+	return ui, nil
+}
+
+// firstRune returns the first character of s, or 0 if s is empty
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// action looks up an action name, which may be empty
+func (ui *UI) action(name string, actions ActionRegistry) (func(s tcell.Screen), error) {
+	if name == "" {
+		return nil, nil
+	}
+	fn, ok := actions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown action %q", name)
+	}
+	return fn, nil
+}
+
+// buildItems converts item definitions into dropdown items
+func (ui *UI) buildItems(defs []MenuItemDefinition, actions ActionRegistry) ([]DropdownItem, error) {
+	items := make([]DropdownItem, 0, len(defs))
+	for _, d := range defs {
+		onSelect, err := ui.action(d.Action, actions)
+		if err != nil {
+			return nil, fmt.Errorf("item %q: %w", d.Text, err)
+		}
+		if d.Dialog != "" {
+			if _, ok := ui.windows[d.Dialog]; !ok {
+				return nil, fmt.Errorf("item %q: unknown dialog %q", d.Text, d.Dialog)
+			}
+			dialog, then := d.Dialog, onSelect
+			onSelect = func(s tcell.Screen) {
+				if then != nil {
+					then(s)
+				}
+				ui.ShowDialog(dialog)
+			}
+		}
+		children, err := ui.buildItems(d.Items, actions)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, DropdownItem{
+			Text:        d.Text,
+			ID:          d.ID,
+			IsSeparator: d.Separator,
+			OnSelect:    onSelect,
+			Items:       children,
+			Shortcut:    d.Shortcut,
+			HotKey:      firstRune(d.HotKey),
+			Disabled:    d.Disabled,
+			Hidden:      d.Hidden,
+			Checkable:   d.Checkable,
+			Checked:     d.Checked,
+			RadioGroup:  d.RadioGroup,
+		})
+	}
+	return items, nil
+}
+
+// addWindow creates a window and its form from a definition
+func (ui *UI) addWindow(d WindowDefinition, dialog bool, actions ActionRegistry) error {
+	if d.ID == "" {
+		return fmt.Errorf("window %q has no id", d.Title)
+	}
+	if _, ok := ui.windows[d.ID]; ok {
+		return fmt.Errorf("duplicate window id %q", d.ID)
+	}
+
+	w := NewWindow(d.Title, d.X, d.Y, d.Width, d.Height)
+	w.Visible = !d.Hidden && !dialog
+	if d.Border != "" {
+		border, ok := BorderByName(d.Border)
+		if !ok {
+			return fmt.Errorf("window %q: unknown border style %q", d.ID, d.Border)
+		}
+		w.Border = border
+	}
+	form := &uiForm{ui: ui, window: w, text: d.Text, focus: -1, center: d.Center || dialog}
+
+	for _, fd := range d.Fields {
+		if _, ok := ui.fields[fd.ID]; ok || fd.ID == "" {
+			return fmt.Errorf("window %q: missing or duplicate field id %q", d.ID, fd.ID)
+		}
+		field := NewComboBox(0, 0, fd.Width, fd.Items)
+		switch fd.Type {
+		case "", "text", "combo":
+			field.Editable = true
+			field.Text = fd.Value
+			field.cursor = len([]rune(fd.Value))
+		case "list":
+			for i, item := range fd.Items {
+				if item == fd.Value {
+					field.SetSelected(i)
+				}
+			}
+		default:
+			return fmt.Errorf("field %q: unknown type %q", fd.ID, fd.Type)
+		}
+		action, err := ui.action(fd.Action, actions)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", fd.ID, err)
+		}
+		if action != nil {
+			field.OnChange = func(text string, index int) {
+				if ui.screen != nil {
+					action(ui.screen)
+				}
+			}
+		}
+		ui.fields[fd.ID] = field
+		form.fields = append(form.fields, field)
+		form.labels = append(form.labels, fd.Label)
+		form.widths = append(form.widths, fd.Width)
+	}
+
+	for _, bd := range d.Buttons {
+		action, err := ui.action(bd.Action, actions)
+		if err != nil {
+			return fmt.Errorf("button %q: %w", bd.Text, err)
+		}
+		if action == nil {
+			action = func(s tcell.Screen) { w.Visible = false }
+		}
+		form.buttons = append(form.buttons, uiButton{text: bd.Text, action: action})
+	}
+
+	w.Content = form.draw
+	ui.windows[d.ID] = w
+	ui.forms[w] = form
+	ui.Windows = append(ui.Windows, w)
+	return nil
+}
+
+// Window returns the window or dialog with the given ID, or nil
+func (ui *UI) Window(id string) *Window {
+	return ui.windows[id]
+}
+
+// Field returns the form field with the given ID, or nil
+func (ui *UI) Field(id string) *ComboBox {
+	return ui.fields[id]
+}
+
+// FocusedField returns the field with keyboard focus in the top-most window,
+// or nil
+func (ui *UI) FocusedField() *ComboBox {
+	top := ui.topWindow()
+	if top == nil {
+		return nil
+	}
+	if form := ui.forms[top]; form.focus >= 0 {
+		return form.fields[form.focus]
+	}
+	return nil
+}
+
+// ShowDialog shows the dialog or window with the given ID centred on top of
+// the other windows. It returns false if there is no such window.
+func (ui *UI) ShowDialog(id string) bool {
+	w := ui.windows[id]
+	if w == nil {
+		return false
+	}
+	w.Visible = true
+	ui.forms[w].center = true
+	ui.raise(w)
+	return true
+}
+
+// raise moves w to the top of the z-order
+func (ui *UI) raise(w *Window) {
+	for i, other := range ui.Windows {
+		if other == w {
+			ui.Windows = append(append(ui.Windows[:i:i], ui.Windows[i+1:]...), w)
+			return
+		}
+	}
+}
+
+// topWindow returns the top-most visible window, or nil
+func (ui *UI) topWindow() *Window {
+	for i := len(ui.Windows) - 1; i >= 0; i-- {
+		if ui.Windows[i].Visible {
+			return ui.Windows[i]
+		}
+	}
+	return nil
+}
+
+// Draw renders the background, windows, status bar and menu bar. The caller
+// shows the screen afterwards.
+func (ui *UI) Draw(s tcell.Screen) {
+	ui.screen = s
+	c := ui.Colors
+	DrawBackground(s, ui.Background, true, ' ')
+
+	sw, sh := s.Size()
+	for _, w := range ui.Windows {
+		if form := ui.forms[w]; form.center && w.Visible {
+			w.X, w.Y = max(0, (sw-w.Width)/2), max(1, (sh-w.Height)/2)
+			form.center = false
+		}
+	}
+	DrawWindows(s, ui.Windows, c.BorderFg, c.BorderBg, c.TitleFg, c.TitleBg, c.ControlFg, c.ControlBg)
+
+	DrawBottomBar(s, ui.Status, c.StatusFg, c.StatusBg)
+	ui.MenuBar.Draw(s)
+}
+
+// HandleEvent routes an event to the menu bar, the fields and buttons of the
+// top-most window, and the windows themselves. It returns true if the event
+// was consumed and the screen should be redrawn.
+func (ui *UI) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	ui.screen = s
+	if ui.Clipboard != nil {
+		ui.Clipboard.Target = nil
+		if field := ui.FocusedField(); field != nil {
+			ui.Clipboard.Target = field
+		}
+		if ui.Clipboard.HandleEvent(s, ev) {
+			return true
+		}
+	}
+	if ui.MenuBar.HandleEvent(s, ev) {
+		return true
+	}
+	if top := ui.topWindow(); top != nil && ui.forms[top].handleEvent(s, ev) {
+		return true
+	}
+	c := ui.Colors
+	return ManageWindows(s, ui.Windows, ev, c.BorderFg, c.BorderBg, c.TitleFg, c.TitleBg, c.ControlFg, c.ControlBg)
+}
+
+// draw is the window content function of a form
+func (f *uiForm) draw(s tcell.Screen, x, y, width, height int) {
+	textStyle := tcell.StyleDefault.Foreground(f.ui.Colors.TextFg).Background(f.ui.Colors.BorderBg)
+	row := y
+	for _, line := range f.text {
+		PrintAt(s, x+1, row, line, textStyle)
+		row++
+	}
+	if len(f.text) > 0 && len(f.fields) > 0 {
+		row++
+	}
+
+	labelWidth := 0
+	for _, label := range f.labels {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(label))
+	}
+	for i, field := range f.fields {
+		PrintAt(s, x+1, row, f.labels[i], textStyle)
+		field.X, field.Y = x+labelWidth+2, row
+		field.Width = f.widths[i]
+		if field.Width <= 0 || field.X+field.Width > x+width-1 {
+			field.Width = x + width - 1 - field.X
+		}
+		row++
+	}
+
+	// Buttons are centred on the last row, one cell apart
+	total := 0
+	for i := range f.buttons {
+		b := &f.buttons[i]
+		b.width = utf8.RuneCountInString(b.text) + 4
+		total += b.width + 1
+	}
+	bx := x + (width-total)/2
+	for i := range f.buttons {
+		b := &f.buttons[i]
+		b.x, b.y = bx, y+height-1
+		PrintAt(s, b.x, b.y, "[ "+b.text+" ]", textStyle)
+		bx += b.width + 1
+	}
+
+	// Fields are drawn bottom-up so an open list covers the fields below it
+	c := f.ui.Colors
+	for i := len(f.fields) - 1; i >= 0; i-- {
+		if !f.fields[i].Open {
+			f.fields[i].Draw(s, c.FieldFg, c.FieldBg, c.ListFg, c.ListBg, c.ActiveFg, c.ActiveBg)
+		}
+	}
+	for _, field := range f.fields {
+		if field.Open {
+			field.Draw(s, c.FieldFg, c.FieldBg, c.ListFg, c.ListBg, c.ActiveFg, c.ActiveBg)
+		}
+	}
+}
+
+// setFocus moves the keyboard focus to field index, or removes it for -1
+func (f *uiForm) setFocus(index int) {
+	if f.focus >= 0 && f.focus != index {
+		f.fields[f.focus].Blur()
+	}
+	f.focus = index
+	if index >= 0 {
+		f.fields[index].Focus()
+	}
+}
+
+// handleEvent processes events for the fields and buttons of a form
+func (f *uiForm) handleEvent(s tcell.Screen, ev tcell.Event) bool {
+	if f.window.State == windowStateMinimized {
+		return false
+	}
+
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		if f.focus >= 0 && f.fields[f.focus].HandleEvent(ev) {
+			return true
+		}
+		switch e.Key() {
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if len(f.fields) == 0 {
+				return false
+			}
+			dir := 1
+			if e.Key() == tcell.KeyBacktab {
+				dir = -1
+			}
+			f.setFocus((f.focus + dir + len(f.fields)) % len(f.fields))
+			return true
+		case tcell.KeyEnter:
+			// Enter presses the first button
+			if len(f.buttons) > 0 {
+				f.buttons[0].action(s)
+				return true
+			}
+		case tcell.KeyEscape:
+			if f.focus >= 0 {
+				f.setFocus(-1)
+				return true
+			}
+		}
+
+	case *tcell.EventMouse:
+		if e.Buttons() != tcell.ButtonPrimary {
+			// Hovering an open list still highlights its rows
+			return f.focus >= 0 && f.fields[f.focus].Open && f.fields[f.focus].HandleEvent(ev)
+		}
+		mouseX, mouseY := e.Position()
+		for i, field := range f.fields {
+			if field.Open || (mouseY == field.Y && mouseX >= field.X && mouseX < field.X+field.Width) {
+				if field.HandleEvent(ev) {
+					f.setFocus(i)
+					return true
+				}
+			}
+		}
+		for _, b := range f.buttons {
+			if mouseY == b.y && mouseX >= b.x && mouseX < b.x+b.width {
+				b.action(s)
+				return true
+			}
+		}
+		// A click elsewhere removes the focus but is left for the windows
+		f.setFocus(-1)
+	}
+	return false
+}
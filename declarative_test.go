@@ -0,0 +1,116 @@
+package retrotui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestLoadUIErrors(t *testing.T) {
+	actions := ActionRegistry{"file.save": func(s tcell.Screen) {}}
+	tests := []struct {
+		name, json, want string
+	}{
+		{"unknown key", `{"menus": [{"title": "File", "itemz": []}]}`, `unknown field "itemz"`},
+		{"unknown item action", `{"menus": [{"title": "File", "items": [{"text": "Open", "action": "file.open"}]}]}`,
+			`unknown action "file.open"`},
+		{"unknown button action", `{"windows": [{"id": "w", "width": 20, "height": 5, "buttons": [{"text": "OK", "action": "ok"}]}]}`,
+			`unknown action "ok"`},
+		{"unknown field action", `{"windows": [{"id": "w", "width": 20, "height": 5, "fields": [{"id": "f", "action": "x"}]}]}`,
+			`unknown action "x"`},
+		{"unknown dialog", `{"menus": [{"title": "File", "items": [{"text": "Find", "dialog": "find"}]}]}`,
+			`unknown dialog "find"`},
+		{"duplicate window id", `{"windows": [{"id": "w", "width": 20, "height": 5}], "dialogs": [{"id": "w", "width": 20, "height": 5}]}`,
+			`duplicate window id "w"`},
+		{"duplicate field id", `{"windows": [{"id": "a", "width": 20, "height": 5, "fields": [{"id": "f"}]},
+			{"id": "b", "width": 20, "height": 5, "fields": [{"id": "f"}]}]}`, `duplicate field id "f"`},
+		{"invalid shortcut", `{"menus": [{"title": "File", "items": [{"text": "Save", "shortcut": "Hyper+S"}]}]}`, `Hyper`},
+		{"missing window id", `{"windows": [{"title": "Untitled", "width": 20, "height": 5}]}`, `has no id`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadUI(strings.NewReader(tt.json), actions)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadUI error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadUI(t *testing.T) {
+	saved := 0
+	actions := ActionRegistry{"file.save": func(s tcell.Screen) { saved++ }}
+	ui, err := LoadUI(strings.NewReader(`{
+		"menus": [{"title": "File", "items": [{"text": "Save", "action": "file.save"}, {"text": "Find", "dialog": "find"}]}],
+		"dialogs": [{"id": "find", "title": "Find", "width": 30, "height": 6, "fields": [{"id": "text", "label": "Text"}]}]
+	}`), actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ui.Window("find") == nil || ui.Window("find").Visible || ui.Field("text") == nil {
+		t.Fatal("dialog or field missing, or dialog shown")
+	}
+	items := ui.MenuBar.Menus[0].Items
+	items[0].OnSelect(nil)
+	items[1].OnSelect(nil)
+	if saved != 1 || !ui.Window("find").Visible {
+		t.Errorf("saved %d times, dialog shown %v", saved, ui.Window("find").Visible)
+	}
+}
+
+func TestUIFormNonASCII(t *testing.T) {
+	s := newSimulationScreen(t, 60, 20)
+	pressed := ""
+	actions := ActionRegistry{
+		"ok":     func(s tcell.Screen) { pressed = "ok" },
+		"cancel": func(s tcell.Screen) { pressed = "cancel" },
+	}
+	ui, err := LoadUI(strings.NewReader(`{"windows": [{"id": "w", "title": "Größe", "x": 0, "y": 0, "width": 40, "height": 8,
+		"fields": [{"id": "name", "label": "Name"}, {"id": "size", "label": "Größe"}],
+		"buttons": [{"text": "Übernehmen", "action": "ok"}, {"text": "Zurück", "action": "cancel"}]}]}`), actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui.Draw(s)
+
+	// The longest label is five characters, whatever their encoding; the
+	// content starts inside the border and labels one cell into it
+	x, _, _, _ := ui.Window("w").GetDimensions(s)
+	x++
+	if got, want := ui.Field("size").X, x+1+5+1; got != want {
+		t.Errorf("field after %q starts at %d, want %d", "Größe", got, want)
+	}
+	row := ui.Field("size").Y
+	var label strings.Builder
+	for col := x + 1; col < x+6; col++ {
+		r, _, _, _ := s.GetContent(col, row)
+		label.WriteRune(r)
+	}
+	if label.String() != "Größe" {
+		t.Errorf("label reads %q", label.String())
+	}
+
+	// Clicking the last cell of each button presses it, the cell after it
+	// does not
+	form := ui.forms[ui.Window("w")]
+	for _, b := range form.buttons {
+		end := b.x + utf8.RuneCountInString("[ "+b.text+" ]") - 1
+		if r, _, _, _ := s.GetContent(end, b.y); r != ']' {
+			t.Errorf("button %q ends in %q", b.text, r)
+		}
+		pressed = ""
+		ui.HandleEvent(s, tcell.NewEventMouse(end, b.y, tcell.ButtonPrimary, tcell.ModNone))
+		ui.HandleEvent(s, tcell.NewEventMouse(end, b.y, tcell.ButtonNone, tcell.ModNone))
+		if want := map[string]string{"Übernehmen": "ok", "Zurück": "cancel"}[b.text]; pressed != want {
+			t.Errorf("clicking the end of %q pressed %q", b.text, pressed)
+		}
+		pressed = ""
+		ui.HandleEvent(s, tcell.NewEventMouse(end+1, b.y, tcell.ButtonPrimary, tcell.ModNone))
+		ui.HandleEvent(s, tcell.NewEventMouse(end+1, b.y, tcell.ButtonNone, tcell.ModNone))
+		if pressed != "" {
+			t.Errorf("clicking past the end of %q pressed %q", b.text, pressed)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// DragPayload is the data carried by a drag
+type DragPayload struct {
+	Type  string // Kind of data, e.g. "file"; drop targets accept or reject by type
+	Data  any
+	Label string // Shown next to the drag glyph
+}
+
+// DropTarget is a window or widget that payloads can be dropped on
+type DropTarget interface {
+	// HitTest reports whether the target occupies screen cell (x, y)
+	HitTest(s tcell.Screen, x, y int) bool
+	// AcceptsDrop reports whether the payload may be dropped at (x, y)
+	AcceptsDrop(p DragPayload, x, y int) bool
+	// Drop receives the payload and reports whether it was taken
+	Drop(s tcell.Screen, p DragPayload, x, y int) bool
+}
+
+// DragSource is told how a drag it started ended, e.g. to remove a moved item
+type DragSource interface {
+	DragEnded(p DragPayload, target DropTarget, dropped bool)
+}
+
+// DragManager runs drag and drop between widgets. A widget starts a drag with
+// Start, usually on a MouseDragStart action; the manager then follows the
+// pointer, draws the drag glyph and delivers the payload to the drop target
+// under the pointer on release. Set MouseTracker.Drag so that the manager
+// receives the mouse events of the drag.
+type DragManager struct {
+	Targets     []DropTarget                           // Top-most first
+	Glyph       rune                                   // Drawn at the pointer during a drag
+	AcceptStyle tcell.Style                            // Glyph style over a target that accepts the payload
+	RejectStyle tcell.Style                            // Glyph style anywhere else
+	OnDrop      func(p DragPayload, target DropTarget) // Called after a successful drop
+
+	active  bool
+	payload DragPayload
+	source  DragSource
+	x, y    int
+}
+
+// NewDragManager creates a drag manager for targets, top-most first
+func NewDragManager(targets ...DropTarget) *DragManager {
+	return &DragManager{
+		Targets:     targets,
+		Glyph:       '■',
+		AcceptStyle: tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorLime),
+		RejectStyle: tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed),
+	}
+}
+
+// Start begins dragging payload from (x, y). source may be nil.
+func (d *DragManager) Start(p DragPayload, source DragSource, x, y int) {
+	d.active = true
+	d.payload = p
+	d.source = source
+	d.x, d.y = x, y
+}
+
+// Active reports whether a drag is in progress
+func (d *DragManager) Active() bool {
+	return d != nil && d.active
+}
+
+// Payload returns the payload of the drag in progress
+func (d *DragManager) Payload() DragPayload {
+	return d.payload
+}
+
+// Cancel abandons the drag in progress
+func (d *DragManager) Cancel() {
+	if !d.active {
+		return
+	}
+	d.active = false
+	if d.source != nil {
+		d.source.DragEnded(d.payload, nil, false)
+	}
+}
+
+// target returns the top-most drop target at (x, y) and whether it accepts
+// the payload
+func (d *DragManager) target(s tcell.Screen, x, y int) (DropTarget, bool) {
+	for _, t := range d.Targets {
+		if t.HitTest(s, x, y) {
+			return t, t.AcceptsDrop(d.payload, x, y)
+		}
+	}
+	return nil, false
+}
+
+// HandleMouse follows the pointer during a drag and drops the payload when the
+// buttons are released. It returns true while a drag is in progress.
+func (d *DragManager) HandleMouse(s tcell.Screen, ev *EventMouseAction) bool {
+	if !d.active {
+		return false
+	}
+	d.x, d.y = ev.X, ev.Y
+
+	switch ev.Action {
+	case MouseDrop, MouseClick:
+		d.active = false
+		target, ok := d.target(s, ev.X, ev.Y)
+		dropped := ok && target.Drop(s, d.payload, ev.X, ev.Y)
+		if d.source != nil {
+			d.source.DragEnded(d.payload, target, dropped)
+		}
+		if dropped && d.OnDrop != nil {
+			d.OnDrop(d.payload, target)
+		}
+	}
+	return true
+}
+
+// HandleEvent cancels the drag in progress when Esc is pressed
+func (d *DragManager) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok && d.Active() && e.Key() == tcell.KeyEscape {
+		d.Cancel()
+		return true
+	}
+	return false
+}
+
+// Draw renders the drag glyph and payload label at the pointer. Call it after
+// everything else so it is on top.
+func (d *DragManager) Draw(s tcell.Screen) {
+	if !d.Active() {
+		return
+	}
+	style := d.RejectStyle
+	if _, ok := d.target(s, d.x, d.y); ok {
+		style = d.AcceptStyle
+	}
+	s.SetContent(d.x, d.y, d.Glyph, nil, style)
+	if d.payload.Label != "" {
+		PrintAt(s, d.x+1, d.y, " "+d.payload.Label+" ", style)
+	}
+}
@@ -0,0 +1,82 @@
+// examples/declarative/main.go — RetroTUI Declarative UI Demo
+package main
+
+import (
+	"os"
+
+	"github.com/earentir/retrotui" // Import from GitHub path
+	"github.com/gdamore/tcell/v2"
+)
+
+// ui is the screen built from ui.json
+var ui *retrotui.UI
+
+// ----------------------------------------------------------------------------
+// Actions bound to the names used in ui.json
+// ----------------------------------------------------------------------------
+var actions = retrotui.ActionRegistry{
+	"quit": func(s tcell.Screen) {
+		s.Fini()
+		os.Exit(0)
+	},
+	"connect": func(s tcell.Screen) {
+		ui.Status = "Connecting to " + ui.Field("user").Text + "@" + ui.Field("server").Text
+	},
+	"copy": func(s tcell.Screen) {
+		if field := ui.FocusedField(); field != nil {
+			ui.Clipboard.Copy(s, field.Text)
+		}
+	},
+	"paste": func(s tcell.Screen) {
+		ui.Clipboard.Paste()
+	},
+	"add-server": func(s tcell.Screen) {
+		server := ui.Field("server")
+		server.Items = append(server.Items, ui.Field("host").Text)
+		ui.Window("connect").Visible = false
+		ui.Status = "Added " + ui.Field("host").Text
+	},
+}
+
+// ----------------------------------------------------------------------------
+// Main function
+// ----------------------------------------------------------------------------
+func main() {
+	path := "ui.json"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+
+	var err error
+	if ui, err = retrotui.LoadUIFile(path, actions); err != nil {
+		panic(err)
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		panic(err)
+	}
+	if err = screen.Init(); err != nil {
+		panic(err)
+	}
+	defer screen.Fini()
+	screen.EnableMouse()
+	ui.Clipboard = retrotui.NewClipboard(screen)
+
+	for {
+		ui.Draw(screen)
+		screen.Show()
+
+		ev := screen.PollEvent()
+		if retrotui.HandleResize(screen, ev) {
+			retrotui.ClampWindows(screen, ui.Windows)
+			continue
+		}
+		if ui.HandleEvent(screen, ev) {
+			continue
+		}
+		if e, ok := ev.(*tcell.EventKey); ok && (e.Key() == tcell.KeyEsc || e.Key() == tcell.KeyCtrlC) {
+			return
+		}
+	}
+}
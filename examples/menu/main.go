@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"os"
+
 	"github.com/earentir/retrotui"
 	"github.com/gdamore/tcell/v2"
 )
@@ -21,11 +24,21 @@ func main() {
 	}
 	defer screen.Fini()
 
-	// Set up key configuration
+	// Set up key bindings
 	keyConfig := retrotui.DefaultKeyConfig()
+	keyConfig.Keymap = retrotui.DefaultKeymap()
 
-	// Add 'x' as an exit key
-	keyConfig.ExitRunes = append(keyConfig.ExitRunes, 'x')
+	// Add 'x' and the Ctrl+K Ctrl+Q chord as exit keys
+	for _, keys := range []string{"x", "Ctrl+K Ctrl+Q"} {
+		if err := keyConfig.Keymap.Bind(retrotui.ScopeGlobal, keys, retrotui.ActionExit); err != nil {
+			panic(err)
+		}
+	}
+
+	// Apply the user's own bindings, if any
+	if err := keyConfig.Keymap.LoadOverridesFile("keys.json"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		panic(err)
+	}
 
 	// Initialize our state
 	state := UIState{
@@ -102,6 +115,11 @@ func main() {
 			handleMouseEvent(screen, e, &state, mainSelectionBg, selectionActiveBg,
 				selectionNumFg, selectionTextFg, titleBarFg, titleBarBg, statusBarFg,
 				statusBarBg, instructionBoxFg, instructionBoxBg)
+		case *tcell.EventResize:
+			retrotui.HandleResize(screen, e)
+			drawUI(screen, &state, titleBarFg, titleBarBg, statusBarFg, statusBarBg,
+				mainSelectionBg, selectionActiveBg, selectionNumFg, selectionTextFg,
+				instructionBoxFg, instructionBoxBg)
 		}
 	}
 }
@@ -2,7 +2,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/earentir/retrotui" // Import from GitHub path
 	"github.com/gdamore/tcell/v2"
@@ -12,18 +15,9 @@ import (
 // Colour scheme
 // ----------------------------------------------------------------------------
 var (
-	AppBackground    = tcell.NewRGBColor(65, 70, 217) // Blue background
-	MenuBarFg        = tcell.ColorWhite
-	MenuBarBg        = tcell.ColorDarkBlue
-	MenuActiveFg     = tcell.ColorWhite
-	MenuActiveBg     = tcell.ColorRed
-	DropdownFg       = tcell.ColorBlack
-	DropdownBg       = tcell.ColorLightGray
-	DropdownActiveFg = tcell.ColorWhite
-	DropdownActiveBg = tcell.ColorBlue
-	SeparatorColor   = tcell.ColorGray
-	StatusBarFg      = tcell.ColorGreen
-	StatusBarBg      = tcell.ColorDarkBlue
+	AppBackground = tcell.NewRGBColor(65, 70, 217) // Blue background
+	StatusBarFg   = tcell.ColorGreen
+	StatusBarBg   = tcell.ColorDarkBlue
 )
 
 // ----------------------------------------------------------------------------
@@ -31,22 +25,54 @@ var (
 // ----------------------------------------------------------------------------
 var (
 	state      retrotui.UIState
-	appMenus   []retrotui.Menu
+	appMenuBar *retrotui.MenuBar
+	appKeymap  = retrotui.DefaultKeymap()
+	appMouse   = retrotui.NewMouseTracker()
+	appDrag    = retrotui.NewDragManager()
+	appPanes   = map[*retrotui.Window]*retrotui.ListBox{} // File lists shown in windows
+	appScreen  tcell.Screen                               // The terminal, without color limits
+	appComp    *retrotui.Compositor                       // Pushes only changed cells to the terminal
+	background *retrotui.Layer                            // Desktop, below the windows
+	overlay    *retrotui.Layer                            // Status bar, menus and dragged files, above the windows
 	appWindows []*retrotui.Window
+	appBorder  = retrotui.BorderDouble // Border style of new windows
+	appTheme   = retrotui.DefaultTheme()
+	appDepth   retrotui.ColorDepth // Colors the desktop is drawn with; zero for the CGA palette
+	lastChoice string
+	recent     []string // Titles of recently opened windows, newest first
 )
 
 // ----------------------------------------------------------------------------
 // Event handling
 // ----------------------------------------------------------------------------
 func handleEvents(s tcell.Screen, ev tcell.Event) bool {
-	// First give top-most window a chance
-	if len(appWindows) > 0 {
-		if retrotui.ManageWindows(s, appWindows, ev,
-			tcell.ColorWhite, tcell.ColorBlue,
-			tcell.ColorYellow, tcell.ColorBlue,
-			tcell.ColorRed, tcell.ColorBlue) {
-			return true
+	// Wheel scrolling, double-clicking window titles and dragging files
+	// between panes; a pane sits above its own window
+	appMouse.Targets = []retrotui.MouseTarget{appMenuBar}
+	appDrag.Targets = nil
+	for i := len(appWindows) - 1; i >= 0; i-- {
+		if pane := appPanes[appWindows[i]]; pane != nil && appWindows[i].Visible {
+			appMouse.Targets = append(appMouse.Targets, pane)
+			appDrag.Targets = append(appDrag.Targets, pane)
 		}
+		appMouse.Targets = append(appMouse.Targets, appWindows[i])
+	}
+	appMouse.Drag = appDrag
+	if appDrag.HandleEvent(s, ev) || appMouse.HandleEvent(s, ev) {
+		drawUI(s)
+		return true
+	}
+
+	// The menu bar goes first so an open dropdown sits above the windows
+	if appMenuBar.HandleEvent(s, ev) {
+		drawUI(s)
+		return true
+	}
+
+	// Then give top-most window a chance
+	if retrotui.HandleWindows(s, appWindows, ev) {
+		drawUI(s)
+		return true
 	}
 
 	switch e := ev.(type) {
@@ -57,140 +83,13 @@ func handleEvents(s tcell.Screen, ev tcell.Event) bool {
 			os.Exit(0)
 		}
 
-		// Menu keyboard navigation
-		if e.Modifiers()&tcell.ModAlt != 0 {
-			for i, menu := range appMenus {
-				if e.Rune() == menu.HotKey {
-					state.ActiveMenu = i
-					state.ActiveMenuItem = 0
-					state.MenuBarActive = true
-					drawUI(s)
-					return true
-				}
-			}
-		} else if state.MenuBarActive {
-			switch e.Key() {
-			case tcell.KeyLeft:
-				if state.ActiveMenu > 0 {
-					state.ActiveMenu--
-				} else {
-					state.ActiveMenu = len(appMenus) - 1
-				}
-				state.ActiveMenuItem = 0
-			case tcell.KeyRight:
-				if state.ActiveMenu < len(appMenus)-1 {
-					state.ActiveMenu++
-				} else {
-					state.ActiveMenu = 0
-				}
-				state.ActiveMenuItem = 0
-			case tcell.KeyUp:
-				if state.ActiveMenuItem > 0 {
-					state.ActiveMenuItem--
-				}
-				if appMenus[state.ActiveMenu].Items[state.ActiveMenuItem].IsSeparator && state.ActiveMenuItem > 0 {
-					state.ActiveMenuItem--
-				}
-			case tcell.KeyDown:
-				if state.ActiveMenuItem < len(appMenus[state.ActiveMenu].Items)-1 {
-					state.ActiveMenuItem++
-				}
-				if appMenus[state.ActiveMenu].Items[state.ActiveMenuItem].IsSeparator && state.ActiveMenuItem < len(appMenus[state.ActiveMenu].Items)-1 {
-					state.ActiveMenuItem++
-				}
-			case tcell.KeyEnter:
-				it := appMenus[state.ActiveMenu].Items[state.ActiveMenuItem]
-				if it.OnSelect != nil && !it.IsSeparator {
-					it.OnSelect(s)
-				}
-				state.MenuBarActive = false
-				state.ActiveMenu = -1
-			case tcell.KeyEscape:
-				state.MenuBarActive = false
-				state.ActiveMenu = -1
-			}
-			drawUI(s)
-		}
-
-	case *tcell.EventMouse:
-		mouseX, mouseY := e.Position()
-		buttons := e.Buttons()
-
-		// Dropdown interactions
-		if state.MenuBarActive {
-			if state.ActiveMenu >= 0 && state.ActiveMenu < len(appMenus) {
-				menu := appMenus[state.ActiveMenu]
-				items := menu.Items
-				menuX := menu.Position
-				menuY := 1
-				if menu.Align {
-					width, _ := s.Size()
-					menuX = width - len(menu.Title) - 2
-				}
-				maxWidth := 0
-				for _, it := range items {
-					if len(it.Text) > maxWidth {
-						maxWidth = len(it.Text)
-					}
-				}
-				menuWidth := maxWidth + 4
-				width, _ := s.Size()
-				if menuX+menuWidth > width {
-					menuX = width - menuWidth
-				}
-
-				if mouseX >= menuX && mouseX < menuX+menuWidth && mouseY > menuY && mouseY < menuY+len(items)+1 {
-					idx := mouseY - menuY - 1
-					if idx >= 0 && idx < len(items) && !items[idx].IsSeparator {
-						state.ActiveMenuItem = idx
-						if buttons == tcell.ButtonPrimary {
-							if items[idx].OnSelect != nil {
-								state.MenuBarActive = false
-								state.ActiveMenu = -1
-								items[idx].OnSelect(s)
-								return true
-							}
-						}
-					}
-					drawUI(s)
-					return true
-				} else if buttons == tcell.ButtonPrimary {
-					state.MenuBarActive = false
-					state.ActiveMenu = -1
-					drawUI(s)
-					return true
-				}
-			}
-		}
+	case *retrotui.EventMenuSelect:
+		lastChoice = e.Item.Text
+		drawUI(s)
 
-		// Click on menu titles
-		if mouseY == 0 && buttons == tcell.ButtonPrimary {
-			for i, menu := range appMenus {
-				menuX := menu.Position
-				if menu.Align {
-					width, _ := s.Size()
-					menuX = width - len(menu.Title) - 2
-				}
-				if mouseX >= menuX && mouseX < menuX+len(menu.Title) {
-					if state.MenuBarActive && state.ActiveMenu == i {
-						state.MenuBarActive = false
-						state.ActiveMenu = -1
-					} else {
-						state.ActiveMenu = i
-						state.ActiveMenuItem = 0
-						state.MenuBarActive = true
-					}
-					drawUI(s)
-					return true
-				}
-			}
-			if state.MenuBarActive {
-				state.MenuBarActive = false
-				state.ActiveMenu = -1
-				drawUI(s)
-				return true
-			}
-		}
+	case *tcell.EventResize:
+		retrotui.HandleResize(s, e)
+		drawUI(s)
 
 	case *tcell.EventError:
 		s.Fini()
@@ -203,40 +102,48 @@ func handleEvents(s tcell.Screen, ev tcell.Event) bool {
 // Drawing functions
 // ----------------------------------------------------------------------------
 func drawUI(s tcell.Screen) {
-	// Background
-	retrotui.DrawBackground(s, state.Background, true, ' ')
+	// Windows are only redrawn when they move or change, except for the top
+	// window and the file panes, whose content follows the keyboard and mouse
+	windows := appComp.WindowLayers(appWindows,
+		tcell.ColorWhite, tcell.ColorBlue,
+		tcell.ColorYellow, tcell.ColorBlue,
+		tcell.ColorRed, tcell.ColorBlue)
+	for i, w := range appWindows {
+		if appPanes[w] != nil || i == len(appWindows)-1 {
+			windows[i].Invalidate()
+		}
+	}
+	overlay.Invalidate()
 
-	// Menu bar
-	retrotui.DrawMenuBar(s, appMenus, state.ActiveMenu, state.MenuBarActive,
-		MenuBarFg, MenuBarBg, MenuActiveFg, MenuActiveBg)
+	appComp.SetStack(slices.Concat([]*retrotui.Layer{background}, windows, []*retrotui.Layer{overlay})...)
+	appComp.Render()
+}
 
-	// Dropdown menu
-	if state.MenuBarActive && state.ActiveMenu >= 0 && state.ActiveMenu < len(appMenus) {
-		retrotui.DrawDropdownMenu(s, appMenus[state.ActiveMenu], state.ActiveMenuItem,
-			DropdownFg, DropdownBg, DropdownActiveFg, DropdownActiveBg, SeparatorColor)
-	}
+// drawBackground draws the desktop
+func drawBackground(s tcell.Screen) {
+	retrotui.DrawBackground(s, state.Background, true, ' ')
 
 	// Placeholder content area
 	width, height := s.Size()
 	retrotui.PrintCentered(s, height/2, 0, width, "Main Application Screen",
 		tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(state.Background))
-	retrotui.PrintCentered(s, height/2+1, 0, width, "Press Alt+F, Alt+E, or Alt+H to activate menus",
+	retrotui.PrintCentered(s, height/2+1, 0, width, "Press Alt+F, Alt+E, or Alt+H (or F10) to activate menus",
 		tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(state.Background))
 	retrotui.PrintCentered(s, height/2+2, 0, width, "Press F3 to exit",
 		tcell.StyleDefault.Foreground(tcell.ColorGreen).Background(state.Background))
+}
 
-	// Visible windows
-	if len(appWindows) > 0 {
-		retrotui.DrawWindows(s, appWindows,
-			tcell.ColorWhite, tcell.ColorBlue,
-			tcell.ColorYellow, tcell.ColorBlue,
-			tcell.ColorRed, tcell.ColorBlue)
+// drawOverlay draws the status bar, the menu bar with any open dropdown and
+// a dragged file
+func drawOverlay(s tcell.Screen) {
+	status := fmt.Sprintf("F3: Quit   Last frame: %d cells", appComp.Stats().LastFrame)
+	if lastChoice != "" {
+		status += "   Last menu choice: " + lastChoice
 	}
+	retrotui.DrawBottomBar(s, status, StatusBarFg, StatusBarBg)
 
-	// Status bar
-	retrotui.DrawBottomBar(s, "F3: Quit", StatusBarFg, StatusBarBg)
-
-	s.Show()
+	appMenuBar.Draw(s)
+	appDrag.Draw(s)
 }
 
 // ----------------------------------------------------------------------------
@@ -248,36 +155,159 @@ func createWindow(s tcell.Screen, title string, content func(s tcell.Screen, x,
 	x, y := (sw-w)/2, (sh-h)/2
 	win := retrotui.NewWindow(title, x, y, w, h)
 	win.Content = content
+	win.Border = appBorder
+	win.ContextMenu = retrotui.NewContextMenu([]retrotui.DropdownItem{
+		{Text: "Centre", OnSelect: func(s tcell.Screen) {
+			sw, sh := s.Size()
+			win.X, win.Y = (sw-win.Width)/2, (sh-win.Height)/2
+		}},
+		{IsSeparator: true},
+		{Text: "Close", OnSelect: func(s tcell.Screen) { win.Visible = false }},
+	})
 	appWindows = append(appWindows, win)
+	recent = append([]string{title}, slices.DeleteFunc(recent, func(r string) bool { return r == title })...)
+	drawUI(s)
+}
+
+// createPanes opens two file list windows side by side. Files can be dragged
+// from one to the other, or within a list to reorder it.
+func createPanes(s tcell.Screen) {
+	sw, sh := s.Size()
+	w, h := min(30, sw/2-2), min(14, sh-4)
+	lists := [][]string{
+		{"AUTOEXEC.BAT", "CONFIG.SYS", "COMMAND.COM", "README.TXT"},
+		{"GAMES", "WORK", "NOTES.TXT"},
+	}
+	for i, title := range []string{"Left", "Right"} {
+		win := retrotui.NewWindow(title, sw/2-w-1+i*(w+2), (sh-h)/2, w, h)
+		win.Border = appBorder
+		pane := retrotui.NewListBox(lists[i])
+		pane.Drag = appDrag
+		pane.DragType = "file"
+		pane.Accepts = []string{"file"}
+		pane.MoveOnDrop = true
+		pane.Keymap = appKeymap
+		win.Content = pane.Draw
+		appPanes[win] = pane
+		appWindows = append(appWindows, win)
+	}
 	drawUI(s)
 }
 
+// createListing opens a window whose content is larger than the window; the
+// mouse wheel scrolls it
+func createListing(s tcell.Screen) {
+	createWindow(s, "Listing", func(sc tcell.Screen, x, y, w, h int) {
+		st := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+		for i := 0; i < h; i++ {
+			retrotui.PrintAt(sc, x+1, y+i, fmt.Sprintf("%3d  %s", i+1, strings.Repeat("RETRO ", 12)), st)
+		}
+	})
+	win := appWindows[len(appWindows)-1]
+	win.VirtualWidth, win.VirtualHeight = 80, 60
+	drawUI(s)
+}
+
+// createTable opens a window with a table whose grid is collected in a
+// LineSet, so the lines are joined where they meet
+func createTable(s tcell.Screen) {
+	rows := [][]string{
+		{"Name", "Size", "Date"},
+		{"AUTOEXEC.BAT", "120", "1994-03-01"},
+		{"CONFIG.SYS", "96", "1994-03-01"},
+		{"COMMAND.COM", "54619", "1993-09-30"},
+	}
+	widths := []int{14, 7, 12}
+	createWindow(s, "Table", func(sc tcell.Screen, x, y, w, h int) {
+		st := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+		tableW, tableH := len(widths)+1, len(rows)+3
+		for _, cw := range widths {
+			tableW += cw
+		}
+
+		lines := retrotui.NewLineSet()
+		lines.Box(x+1, y, tableW, tableH, retrotui.LineDouble, retrotui.LineDouble, st)
+		lines.HLine(x+1, y+2, tableW, retrotui.LineSingle, st)
+		cx := x + 1
+		for _, cw := range widths[:len(widths)-1] {
+			cx += cw + 1
+			lines.VLine(cx, y, tableH, retrotui.LineSingle, st)
+		}
+		lines.Draw(sc)
+
+		for i, row := range rows {
+			ry := y + 1 + i
+			if i > 0 {
+				ry++ // Below the header line
+			}
+			cx := x + 2
+			for j, text := range row {
+				retrotui.PrintAt(sc, cx, ry, text, st)
+				cx += widths[j] + 1
+			}
+		}
+	})
+}
+
+// openWindows returns the number of visible windows
+func openWindows() int {
+	n := 0
+	for _, w := range appWindows {
+		if w.Visible {
+			n++
+		}
+	}
+	return n
+}
+
+// rebuildRecent fills the Recent submenu from the recent window titles
+func rebuildRecent(m *retrotui.Menu) {
+	item := appMenuBar.FindItem("recent")
+	item.Items = nil
+	for _, title := range recent {
+		item.Items = append(item.Items, retrotui.DropdownItem{Text: title})
+	}
+	item.Disabled = len(recent) == 0
+}
+
 // ----------------------------------------------------------------------------
 // Menu initialization
 // ----------------------------------------------------------------------------
-func initialiseMenus(screen tcell.Screen) {
-	appMenus = []retrotui.Menu{
+func initialiseMenus() {
+	menus := []retrotui.Menu{
 		{
-			Title: "File", HotKey: 'f', Position: 1,
+			Title: "File", HotKey: 'f', OnOpen: rebuildRecent,
 			Items: []retrotui.DropdownItem{
-				{Text: "Open...", OnSelect: func(s tcell.Screen) {
+				{Text: "Open...", Shortcut: "Ctrl+O", OnSelect: func(s tcell.Screen) {
 					createWindow(s, "Open", func(sc tcell.Screen, x, y, w, h int) {
 						st := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
 						retrotui.PrintAt(sc, x+2, y+2, "Open dialog placeholder", st)
 					})
 				}},
-				{Text: "Save", OnSelect: func(s tcell.Screen) {
+				{Text: "Save", Shortcut: "Ctrl+S", EnabledFunc: func() bool { return openWindows() > 0 }, OnSelect: func(s tcell.Screen) {
 					createWindow(s, "Save", func(sc tcell.Screen, x, y, w, h int) {
 						st := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
 						retrotui.PrintAt(sc, x+2, y+2, "Save placeholder", st)
 					})
 				}},
+				{Text: "File panes", OnSelect: createPanes},
+				{Text: "Long listing", OnSelect: createListing},
+				{Text: "Table", OnSelect: createTable},
+				{Text: "Recent", ID: "recent"},
+				{ID: "close-all",
+					LabelFunc:   func() string { return fmt.Sprintf("Close %d windows", openWindows()) },
+					VisibleFunc: func() bool { return openWindows() > 1 },
+					OnSelect: func(s tcell.Screen) {
+						for _, w := range appWindows {
+							w.Visible = false
+						}
+					}},
 				{IsSeparator: true},
-				{Text: "Exit", OnSelect: func(s tcell.Screen) { s.Fini(); os.Exit(0) }},
+				{Text: "Exit", Shortcut: "Alt+X", OnSelect: func(s tcell.Screen) { s.Fini(); os.Exit(0) }},
 			},
 		},
 		{
-			Title: "Edit", HotKey: 'e', Position: 6,
+			Title: "Edit", HotKey: 'e',
 			Items: []retrotui.DropdownItem{
 				{Text: "Copy", OnSelect: func(s tcell.Screen) {
 					createWindow(s, "Copy", func(sc tcell.Screen, x, y, w, h int) {
@@ -285,6 +315,24 @@ func initialiseMenus(screen tcell.Screen) {
 						retrotui.PrintCentered(sc, y+h/2, x, w, "Copy placeholder", st)
 					})
 				}},
+				{Text: "Paste", Shortcut: "Ctrl+V", Disabled: true},
+				{IsSeparator: true},
+				{Text: "Word wrap", Checkable: true, Checked: true},
+				{Text: "Insert mode", RadioGroup: "mode", Checked: true},
+				{Text: "Overwrite mode", RadioGroup: "mode"},
+				{IsSeparator: true},
+				{Text: "Keys", Items: keyPresetItems()},
+				{Text: "Borders", Items: borderItems()},
+				{Text: "Colors", Items: colorItems()},
+				{Text: "Transform", Items: []retrotui.DropdownItem{
+					{Text: "Upper case"},
+					{Text: "Lower case"},
+					{IsSeparator: true},
+					{Text: "Encoding", Items: []retrotui.DropdownItem{
+						{Text: "UTF-8"},
+						{Text: "CP437"},
+					}},
+				}},
 			},
 		},
 		{
@@ -299,8 +347,89 @@ func initialiseMenus(screen tcell.Screen) {
 			},
 		},
 	}
-	width, _ := screen.Size()
-	appMenus[len(appMenus)-1].Position = width - len(appMenus[len(appMenus)-1].Title) - 2
+	var err error
+	if appMenuBar, err = retrotui.NewMenuBar(menus); err != nil {
+		panic(err)
+	}
+	appMenuBar.Keymap = appKeymap
+}
+
+// keyPresetItems returns radio items that switch the navigation keys of the
+// menus and lists
+func keyPresetItems() []retrotui.DropdownItem {
+	var items []retrotui.DropdownItem
+	for _, preset := range retrotui.KeyPresets() {
+		items = append(items, retrotui.DropdownItem{
+			Text:       string(preset),
+			RadioGroup: "keys",
+			Checked:    preset == retrotui.PresetDefault,
+			OnSelect: func(s tcell.Screen) {
+				if err := appKeymap.UsePreset(preset); err != nil {
+					lastChoice = err.Error()
+				}
+			},
+		})
+	}
+	return items
+}
+
+// borderItems returns radio items that switch the border style of the windows
+func borderItems() []retrotui.DropdownItem {
+	var items []retrotui.DropdownItem
+	for _, border := range retrotui.BorderStyles() {
+		items = append(items, retrotui.DropdownItem{
+			Text:       border.Name,
+			RadioGroup: "borders",
+			Checked:    border.Name == appBorder.Name,
+			OnSelect: func(s tcell.Screen) {
+				appBorder = border
+				for _, w := range appWindows {
+					w.Border = border
+				}
+			},
+		})
+	}
+	return items
+}
+
+// colorItems returns radio items that limit the colors the desktop is drawn with
+func colorItems() []retrotui.DropdownItem {
+	depths := []struct {
+		name  string
+		depth retrotui.ColorDepth
+	}{
+		{"True color", retrotui.DepthTrueColor},
+		{"256 colors", retrotui.Depth256},
+		{"16 colors", retrotui.Depth16},
+		{"8 colors", retrotui.Depth8},
+		{"Monochrome", retrotui.DepthMono},
+		{"CGA palette", 0},
+	}
+	var items []retrotui.DropdownItem
+	for _, d := range depths {
+		items = append(items, retrotui.DropdownItem{
+			Text:       d.name,
+			RadioGroup: "colors",
+			Checked:    d.depth == appDepth,
+			OnSelect:   func(s tcell.Screen) { useColorDepth(d.depth) },
+		})
+	}
+	return items
+}
+
+// useColorDepth draws the desktop with the theme for the given color depth,
+// or with zero in the 16 colors of the IBM text modes
+func useColorDepth(depth retrotui.ColorDepth) {
+	appDepth = depth
+	theme, screen := appTheme.For(depth), retrotui.LimitColors(appScreen, depth)
+	if depth == 0 {
+		theme, screen = appTheme.CGA().For(retrotui.Depth16), retrotui.ForceCGA(appScreen, retrotui.BlinkBright)
+	}
+	state.Background = theme.Background
+	appMenuBar.Colors = theme.Menu
+	appComp.Screen = screen
+	appComp.Invalidate()
+	appComp.Sync()
 }
 
 // ----------------------------------------------------------------------------
@@ -320,16 +449,20 @@ func main() {
 
 	// Initialize application state
 	state = retrotui.UIState{
-		Background:     AppBackground,
-		ActiveMenu:     -1,
-		ActiveMenuItem: -1,
-		MenuBarActive:  true,
+		Background: AppBackground,
+		ActiveMenu: -1,
 	}
 
 	// Initialize windows and menus
 	appWindows = make([]*retrotui.Window, 0)
-	initialiseMenus(screen)
+	appDepth = retrotui.DetectColorDepth(screen)
+	initialiseMenus()
 
+	appScreen = screen
+	appComp = retrotui.NewCompositor(screen)
+	background = appComp.NewLayer(drawBackground)
+	overlay = appComp.NewLayer(drawOverlay)
+	useColorDepth(appDepth)
 	drawUI(screen)
 
 	for {
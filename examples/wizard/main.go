@@ -35,8 +35,52 @@ var (
 	}
 	wizardIndex  = 0 // current page
 	wizardBtnIdx = 1 // 0=Back, 1=Next/Finish, 2=Cancel
+
+	// The wizard box is centred and at most 60x12, shrinking on small screens
+	wizardGrid = &retrotui.GridLayout{
+		Rows:    []retrotui.GridTrack{{}, {Max: 12, Flex: 100}, {}},
+		Columns: []retrotui.GridTrack{{}, {Max: 60, Flex: 100}, {}},
+		Cells:   []retrotui.GridCell{{Row: 1, Col: 1}},
+	}
+	// The buttons sit at the right of the bottom edge of the box
+	wizardButtons = &retrotui.BoxLayout{
+		Direction: retrotui.LayoutHorizontal,
+		Items:     []retrotui.LayoutItem{{Flex: 1}, {Fixed: 8}, {Fixed: 8}, {Fixed: 8}},
+	}
 )
 
+// layoutWizard returns the wizard box and its buttons for the current screen
+// size, for drawing and for hit testing
+func layoutWizard(s tcell.Screen) (box retrotui.Rect, buttons []retrotui.Rect) {
+	sw, sh := s.Size()
+	box = wizardGrid.Layout(0, 0, sw, sh)[0]
+	buttons = wizardButtons.Layout(box.X, box.Y+box.Height-3, box.Width, 3)[1:]
+	return box, buttons
+}
+
+// pressButton carries out the selected button
+func pressButton(s tcell.Screen) {
+	switch wizardBtnIdx {
+	case 0: // Back
+		if wizardIndex > 0 {
+			wizardIndex--
+			wizardBtnIdx = 1
+		}
+	case 1: // Next or Finish
+		if wizardIndex < len(wizardPages)-1 {
+			wizardIndex++
+			wizardBtnIdx = 1
+		} else {
+			// At the last page - Finish button
+			s.Fini()
+			os.Exit(0)
+		}
+	case 2: // Cancel
+		s.Fini()
+		os.Exit(0)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Event handling
 // ----------------------------------------------------------------------------
@@ -58,25 +102,7 @@ func handleEvents(s tcell.Screen, ev tcell.Event) bool {
 			}
 			drawWizardUI(s)
 		case tcell.KeyEnter:
-			switch wizardBtnIdx {
-			case 0: // Back
-				if wizardIndex > 0 {
-					wizardIndex--
-					wizardBtnIdx = 1
-				}
-			case 1: // Next or Finish
-				if wizardIndex < len(wizardPages)-1 {
-					wizardIndex++
-					wizardBtnIdx = 1
-				} else {
-					// At the last page - Finish button
-					s.Fini()
-					os.Exit(0)
-				}
-			case 2: // Cancel
-				s.Fini()
-				os.Exit(0)
-			}
+			pressButton(s)
 			drawWizardUI(s)
 		}
 	case *tcell.EventMouse:
@@ -84,44 +110,19 @@ func handleEvents(s tcell.Screen, ev tcell.Event) bool {
 		buttons := e.Buttons()
 
 		if buttons == tcell.ButtonPrimary {
-			// Simple button detection
-			sw, sh := s.Size()
-			boxW, boxH := 60, 12
-			boxX, boxY := (sw-boxW)/2, (sh-boxH)/2
-			btnY := boxY + boxH - 3
-			btnX := boxX + boxW - 8*3
-
-			// Check if clicking on a button
-			for i := 0; i < 3; i++ {
-				if mouseX >= btnX+i*8 && mouseX < btnX+i*8+8 &&
-					mouseY >= btnY && mouseY < btnY+3 {
+			_, rects := layoutWizard(s)
+			for i, r := range rects {
+				if r.Contains(mouseX, mouseY) {
 					wizardBtnIdx = i
-
-					// Simulate pressing Enter on this button
-					switch wizardBtnIdx {
-					case 0: // Back
-						if wizardIndex > 0 {
-							wizardIndex--
-							wizardBtnIdx = 1
-						}
-					case 1: // Next or Finish
-						if wizardIndex < len(wizardPages)-1 {
-							wizardIndex++
-							wizardBtnIdx = 1
-						} else {
-							// At the last page - Finish button
-							s.Fini()
-							os.Exit(0)
-						}
-					case 2: // Cancel
-						s.Fini()
-						os.Exit(0)
-					}
+					pressButton(s)
 					drawWizardUI(s)
 					break
 				}
 			}
 		}
+	case *tcell.EventResize:
+		retrotui.HandleResize(s, e)
+		drawWizardUI(s)
 	case *tcell.EventError:
 		s.Fini()
 		panic(e.Error())
@@ -135,11 +136,10 @@ func handleEvents(s tcell.Screen, ev tcell.Event) bool {
 func drawWizardUI(s tcell.Screen) {
 	retrotui.DrawBackground(s, AppBackground, true, ' ')
 
-	// Wizard box dimensions
-	sw, sh := s.Size()
-	boxW, boxH := 60, 12
-	boxX, boxY := (sw-boxW)/2, (sh-boxH)/2
-	retrotui.DrawBox(s, boxX, boxY, boxW, boxH, tcell.ColorBlack, WizardBoxBg, retrotui.DrawOptions{ShadowEnabled: true})
+	box, buttons := layoutWizard(s)
+	boxX, boxY, boxW := box.X, box.Y, box.Width
+	retrotui.DrawBox(s, boxX, boxY, boxW, box.Height, tcell.ColorBlack, WizardBoxBg, retrotui.DrawOptions{
+		ShadowEnabled: true, ShadowMode: retrotui.ShadowTransparent, ShadowOffsetX: 2, ShadowOffsetY: 1})
 
 	// Draw title and content
 	page := wizardPages[wizardIndex]
@@ -160,15 +160,15 @@ func drawWizardUI(s tcell.Screen) {
 		btnLabels[1] = "Finish"
 	}
 
-	btnY := boxY + boxH - 3
-	btnX := boxX + boxW - 8*3
 	for i, lbl := range btnLabels {
+		b := buttons[i]
 		style := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorGray)
 		if i == wizardBtnIdx {
 			style = tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed)
 		}
-		retrotui.DrawBox(s, btnX+i*8, btnY, 8, 3, tcell.ColorWhite, tcell.ColorBlack, retrotui.DrawOptions{})
-		retrotui.PrintCentered(s, btnY+1, btnX+i*8, 8, lbl, style)
+		// The buttons sit on the bottom edge of the box and join it
+		retrotui.DrawBox(s, b.X, b.Y, b.Width, b.Height, tcell.ColorWhite, tcell.ColorBlack, retrotui.DrawOptions{MergeBorders: true})
+		retrotui.PrintCentered(s, b.Y+1, b.X, b.Width, lbl, style)
 	}
 
 	// Status bar
@@ -0,0 +1,418 @@
+package retrotui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Names of the built-in actions
+const (
+	ActionNavUp     = "nav.up"
+	ActionNavDown   = "nav.down"
+	ActionNavLeft   = "nav.left"
+	ActionNavRight  = "nav.right"
+	ActionNavTop    = "nav.top"
+	ActionNavBottom = "nav.bottom"
+	ActionSelect    = "nav.select"
+	ActionExit      = "app.exit"
+)
+
+// navigationActions maps the built-in action names to navigation actions
+var navigationActions = map[string]NavigationAction{
+	ActionNavUp:     NavUp,
+	ActionNavDown:   NavDown,
+	ActionNavLeft:   NavLeft,
+	ActionNavRight:  NavRight,
+	ActionNavTop:    NavTop,
+	ActionNavBottom: NavBottom,
+	ActionSelect:    NavSelect,
+	ActionExit:      NavExit,
+}
+
+// navKeys maps navigation actions to the keys understood by the built-in
+// menus and lists
+var navKeys = map[string]tcell.Key{
+	ActionNavUp:     tcell.KeyUp,
+	ActionNavDown:   tcell.KeyDown,
+	ActionNavLeft:   tcell.KeyLeft,
+	ActionNavRight:  tcell.KeyRight,
+	ActionNavTop:    tcell.KeyHome,
+	ActionNavBottom: tcell.KeyEnd,
+	ActionSelect:    tcell.KeyEnter,
+}
+
+// NavigationFor returns the navigation action of a built-in action name, or
+// NavNone for any other action
+func NavigationFor(action string) NavigationAction {
+	return navigationActions[action]
+}
+
+// ScopeGlobal is the scope of bindings that apply everywhere
+const ScopeGlobal = "global"
+
+// WindowScope returns the scope of bindings that apply while the named window
+// is active
+func WindowScope(name string) string {
+	return "window:" + name
+}
+
+// WidgetScope returns the scope of bindings that apply while the named widget
+// has focus
+func WidgetScope(name string) string {
+	return "widget:" + name
+}
+
+// KeySequence is one or more key combinations pressed in turn. Sequences of
+// more than one key are chords, such as "Ctrl+K Ctrl+B".
+type KeySequence []KeyCombo
+
+// ParseKeySequence parses space-separated key combinations, each in the form
+// accepted by ParseKeyCombo
+func ParseKeySequence(text string) (KeySequence, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	seq := make(KeySequence, 0, len(fields))
+	for _, field := range fields {
+		combo, err := ParseKeyCombo(field)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, combo)
+	}
+	return seq, nil
+}
+
+// String returns the sequence in the form accepted by ParseKeySequence
+func (k KeySequence) String() string {
+	parts := make([]string, len(k))
+	for i, combo := range k {
+		parts[i] = combo.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasPrefix reports whether prefix is the start of k, or all of it
+func (k KeySequence) hasPrefix(prefix KeySequence) bool {
+	return len(prefix) <= len(k) && slices.Equal(k[:len(prefix)], prefix)
+}
+
+// keyBinding links a key sequence to an action
+type keyBinding struct {
+	keys   KeySequence
+	action string
+}
+
+// Keymap maps key sequences to named actions. Bindings belong to a scope:
+// ScopeGlobal, a WindowScope or a WidgetScope; when resolving a key the most
+// specific scope is tried first. A Keymap also tracks a chord in progress, so
+// a single Keymap should be fed the events of one screen.
+type Keymap struct {
+	scopes  map[string][]keyBinding
+	pending []*tcell.EventKey // Keys of a chord in progress
+}
+
+// NewKeymap creates an empty keymap
+func NewKeymap() *Keymap {
+	return &Keymap{scopes: make(map[string][]keyBinding)}
+}
+
+// DefaultKeymap returns a keymap with the bindings of PresetDefault: those of
+// DefaultKeyConfig, plus Home and End for the first and last item
+func DefaultKeymap() *Keymap {
+	m := NewKeymap()
+	bindings := keyPresets[PresetDefault]
+	for _, action := range slices.Sorted(maps.Keys(bindings)) {
+		for _, keys := range bindings[action] {
+			m.scopes[ScopeGlobal] = append(m.scopes[ScopeGlobal], keyBinding{keys: mustParse(keys), action: action})
+		}
+	}
+	return m
+}
+
+// mustParse parses a key sequence of the built-in tables, which are fixed and
+// known to be valid
+func mustParse(keys string) KeySequence {
+	seq, err := ParseKeySequence(keys)
+	if err != nil {
+		panic(err)
+	}
+	return seq
+}
+
+// Bind binds a key sequence to an action in scope. It returns an error if the
+// sequence cannot be parsed, or if it clashes with a binding of the scope:
+// the same sequence, or one that is a prefix of the other, since the longer
+// chord could then never be completed.
+func (m *Keymap) Bind(scope, keys, action string) error {
+	seq, err := ParseKeySequence(keys)
+	if err != nil {
+		return err
+	}
+	for _, b := range m.scopes[scope] {
+		if b.keys.hasPrefix(seq) || seq.hasPrefix(b.keys) {
+			return fmt.Errorf("%s: %s for %q conflicts with %s for %q", scope, seq, action, b.keys, b.action)
+		}
+	}
+	m.scopes[scope] = append(m.scopes[scope], keyBinding{keys: seq, action: action})
+	return nil
+}
+
+// Unbind removes every binding of action in scope
+func (m *Keymap) Unbind(scope, action string) {
+	m.scopes[scope] = slices.DeleteFunc(m.scopes[scope], func(b keyBinding) bool {
+		return b.action == action
+	})
+}
+
+// SetBindings replaces the keys bound to action in scope. The previous
+// bindings are kept if any of the new keys is invalid or conflicts.
+func (m *Keymap) SetBindings(scope, action string, keys ...string) error {
+	saved := slices.Clone(m.scopes[scope])
+	m.Unbind(scope, action)
+	for _, k := range keys {
+		if err := m.Bind(scope, k, action); err != nil {
+			m.scopes[scope] = saved
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys returns the key sequences bound to action in scope, for display in
+// menus and help screens
+func (m *Keymap) Keys(scope, action string) []string {
+	var keys []string
+	for _, b := range m.scopes[scope] {
+		if b.action == action {
+			keys = append(keys, b.keys.String())
+		}
+	}
+	return keys
+}
+
+// LoadOverridesFile reads user overrides from a JSON file, see LoadOverrides
+func (m *Keymap) LoadOverridesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.LoadOverrides(f)
+}
+
+// update runs fn and restores the bindings if it fails
+func (m *Keymap) update(fn func() error) error {
+	saved := make(map[string][]keyBinding, len(m.scopes))
+	for scope, bindings := range m.scopes {
+		saved[scope] = slices.Clone(bindings)
+	}
+	if err := fn(); err != nil {
+		m.scopes = saved
+		return err
+	}
+	return nil
+}
+
+// LoadOverrides reads user overrides as JSON, mapping scopes to actions and
+// their keys, and replaces the keys of every action listed:
+//
+//	{"global": {"nav.up": ["Up", "k"]}, "window:editor": {"file.save": ["Ctrl+K Ctrl+S"]}}
+//
+// An empty key list unbinds the action. Nothing is changed if an override is
+// invalid or conflicts with another binding.
+func (m *Keymap) LoadOverrides(r io.Reader) error {
+	var overrides map[string]map[string][]string
+	if err := json.NewDecoder(r).Decode(&overrides); err != nil {
+		return fmt.Errorf("reading key overrides: %w", err)
+	}
+
+	// Apply in a fixed order so conflicts are reported consistently
+	return m.update(func() error {
+		for _, scope := range slices.Sorted(maps.Keys(overrides)) {
+			actions := overrides[scope]
+			for _, action := range slices.Sorted(maps.Keys(actions)) {
+				if err := m.SetBindings(scope, action, actions[action]...); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Resolve feeds a key event to the keymap and returns the action it
+// completes. scopes lists the active scopes, most specific first; ScopeGlobal
+// is always tried last. pending is true when the key started or continued a
+// chord, in which case the caller should treat the key as consumed.
+func (m *Keymap) Resolve(ev tcell.Event, scopes ...string) (action string, pending bool) {
+	e, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return "", false
+	}
+	keys := append(m.pending, e)
+	m.pending = nil
+
+	for _, scope := range append(scopes, ScopeGlobal) {
+		prefix := false
+		for _, b := range m.scopes[scope] {
+			switch b.matches(keys) {
+			case len(b.keys):
+				return b.action, false
+			case len(keys):
+				prefix = true
+			}
+		}
+		if prefix {
+			m.pending = keys
+			return "", true
+		}
+	}
+	// A chord that went wrong drops its earlier keys, but the last one may
+	// still mean something on its own
+	if len(keys) > 1 {
+		return m.Resolve(e, scopes...)
+	}
+	return "", false
+}
+
+// matches returns how many keys of the binding the events match in order, or
+// -1 if they differ
+func (b keyBinding) matches(keys []*tcell.EventKey) int {
+	if len(keys) > len(b.keys) {
+		return -1
+	}
+	for i, e := range keys {
+		if !b.keys[i].Matches(e) {
+			return -1
+		}
+	}
+	return len(keys)
+}
+
+// Pending returns the keys of a chord in progress, e.g. "Ctrl+K", or an empty
+// string
+func (m *Keymap) Pending() string {
+	parts := make([]string, len(m.pending))
+	for i, e := range m.pending {
+		parts[i] = strings.TrimSpace(e.Name())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Reset abandons a chord in progress
+func (m *Keymap) Reset() {
+	m.pending = nil
+}
+
+// translate resolves a key event with the keymap and returns the equivalent
+// arrow, Home, End or Enter key for navigation actions, so widgets written
+// for those keys follow the keymap. Other keys are returned unchanged.
+// consumed is true when e is part of an unfinished chord. A nil keymap
+// returns e unchanged.
+func (m *Keymap) translate(e *tcell.EventKey, scopes ...string) (ev *tcell.EventKey, consumed bool) {
+	if m == nil {
+		return e, false
+	}
+	action, pending := m.Resolve(e, scopes...)
+	if pending {
+		return e, true
+	}
+	if key, ok := navKeys[action]; ok {
+		return tcell.NewEventKey(key, 0, tcell.ModNone), false
+	}
+	return e, false
+}
+
+// KeyPreset names a built-in set of navigation bindings
+type KeyPreset string
+
+// Built-in key presets. All of them keep the arrow keys, Home, End and Enter.
+const (
+	PresetDefault  KeyPreset = "default"
+	PresetVim      KeyPreset = "vim"      // hjkl, gg and G
+	PresetWordStar KeyPreset = "wordstar" // The Ctrl+E/S/D/X diamond, Ctrl+Q R and Ctrl+Q C
+	PresetEmacs    KeyPreset = "emacs"    // Ctrl+P/N/B/F, Alt+< and Alt+>
+)
+
+// keyPresets holds the global bindings of each preset
+var keyPresets = map[KeyPreset]map[string][]string{
+	PresetDefault: {
+		ActionNavUp:     {"Up"},
+		ActionNavDown:   {"Down"},
+		ActionNavLeft:   {"Left"},
+		ActionNavRight:  {"Right"},
+		ActionNavTop:    {"Home"},
+		ActionNavBottom: {"End"},
+		ActionSelect:    {"Enter"},
+		ActionExit:      {"Esc", "F3", "Ctrl+C", "q"},
+	},
+	PresetVim: {
+		ActionNavUp:     {"Up", "k"},
+		ActionNavDown:   {"Down", "j"},
+		ActionNavLeft:   {"Left", "h"},
+		ActionNavRight:  {"Right", "l"},
+		ActionNavTop:    {"Home", "g g"},
+		ActionNavBottom: {"End", "G"},
+		ActionSelect:    {"Enter"},
+		ActionExit:      {"Esc", "F3", "Ctrl+C", "q"},
+	},
+	PresetWordStar: {
+		ActionNavUp:     {"Up", "Ctrl+E"},
+		ActionNavDown:   {"Down", "Ctrl+X"},
+		ActionNavLeft:   {"Left", "Ctrl+S"},
+		ActionNavRight:  {"Right", "Ctrl+D"},
+		ActionNavTop:    {"Home", "Ctrl+Q R"},
+		ActionNavBottom: {"End", "Ctrl+Q C"},
+		ActionSelect:    {"Enter"},
+		ActionExit:      {"Esc", "F3", "Ctrl+C"},
+	},
+	PresetEmacs: {
+		ActionNavUp:     {"Up", "Ctrl+P"},
+		ActionNavDown:   {"Down", "Ctrl+N"},
+		ActionNavLeft:   {"Left", "Ctrl+B"},
+		ActionNavRight:  {"Right", "Ctrl+F"},
+		ActionNavTop:    {"Home", "Alt+<"},
+		ActionNavBottom: {"End", "Alt+>"},
+		ActionSelect:    {"Enter"},
+		ActionExit:      {"Esc", "F3", "Ctrl+X Ctrl+C"},
+	},
+}
+
+// KeyPresets returns the names of the built-in presets
+func KeyPresets() []KeyPreset {
+	return []KeyPreset{PresetDefault, PresetVim, PresetWordStar, PresetEmacs}
+}
+
+// UsePreset replaces the global navigation and exit bindings with those of a
+// preset. Other bindings are kept, so a preset can be switched at runtime
+// without losing application actions. Nothing is changed if the preset
+// conflicts with an existing binding.
+func (m *Keymap) UsePreset(preset KeyPreset) error {
+	bindings, ok := keyPresets[preset]
+	if !ok {
+		return fmt.Errorf("unknown key preset %q", preset)
+	}
+	return m.update(func() error {
+		// Drop the previous preset first, as its keys may clash with the new one
+		for action := range bindings {
+			m.Unbind(ScopeGlobal, action)
+		}
+		for _, action := range slices.Sorted(maps.Keys(bindings)) {
+			for _, keys := range bindings[action] {
+				if err := m.Bind(ScopeGlobal, keys, action); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package retrotui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDefaultKeymapMatchesPreset(t *testing.T) {
+	m := NewKeymap()
+	if err := m.UsePreset(PresetDefault); err != nil {
+		t.Fatal(err)
+	}
+	if got := DefaultKeymap(); !reflect.DeepEqual(got, m) {
+		t.Errorf("DefaultKeymap() = %v, want the bindings of PresetDefault %v", got.scopes, m.scopes)
+	}
+}
+
+func TestUsePresets(t *testing.T) {
+	for _, preset := range KeyPresets() {
+		if err := DefaultKeymap().UsePreset(preset); err != nil {
+			t.Errorf("UsePreset(%q): %v", preset, err)
+		}
+	}
+}
+
+func TestResolveFailedChord(t *testing.T) {
+	key := func(k tcell.Key) *tcell.EventKey { return tcell.NewEventKey(k, 0, tcell.ModNone) }
+	char := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+	tests := []struct {
+		preset KeyPreset
+		name   string
+		keys   []*tcell.EventKey
+		want   string
+	}{
+		{PresetVim, "g g", []*tcell.EventKey{char('g'), char('g')}, ActionNavTop},
+		{PresetVim, "g j", []*tcell.EventKey{char('g'), char('j')}, ActionNavDown},
+		{PresetVim, "g x", []*tcell.EventKey{char('g'), char('x')}, ""},
+		{PresetWordStar, "Ctrl+Q R", []*tcell.EventKey{key(tcell.KeyCtrlQ), char('R')}, ActionNavTop},
+		{PresetWordStar, "Ctrl+Q Ctrl+X", []*tcell.EventKey{key(tcell.KeyCtrlQ), key(tcell.KeyCtrlX)}, ActionNavDown},
+		{PresetEmacs, "Ctrl+X Ctrl+P", []*tcell.EventKey{key(tcell.KeyCtrlX), key(tcell.KeyCtrlP)}, ActionNavUp},
+	}
+	for _, tt := range tests {
+		m := NewKeymap()
+		if err := m.UsePreset(tt.preset); err != nil {
+			t.Fatal(err)
+		}
+		var action string
+		for i, e := range tt.keys {
+			var pending bool
+			action, pending = m.Resolve(e)
+			if last := i == len(tt.keys)-1; pending == last {
+				t.Fatalf("%s: key %d of %s pending = %v", tt.preset, i+1, tt.name, pending)
+			}
+		}
+		if action != tt.want {
+			t.Errorf("%s: %s resolved to %q, want %q", tt.preset, tt.name, action, tt.want)
+		}
+		if m.Pending() != "" {
+			t.Errorf("%s: chord %q still pending", tt.preset, m.Pending())
+		}
+	}
+}
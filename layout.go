@@ -0,0 +1,360 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Rect is a rectangle in screen cells
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Contains reports whether the point (x, y) lies within the rectangle
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Inset returns the rectangle shrunk by the given insets
+func (r Rect) Inset(in Insets) Rect {
+	return Rect{
+		X:      r.X + in.Left,
+		Y:      r.Y + in.Top,
+		Width:  max(0, r.Width-in.Left-in.Right),
+		Height: max(0, r.Height-in.Top-in.Bottom),
+	}
+}
+
+// Insets describes space around the edges of a rectangle, used for padding and margins
+type Insets struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+}
+
+// UniformInsets returns insets of n cells on every side
+func UniformInsets(n int) Insets {
+	return Insets{Top: n, Right: n, Bottom: n, Left: n}
+}
+
+// LayoutDirection selects the main axis of a BoxLayout
+type LayoutDirection int
+
+const (
+	LayoutHorizontal LayoutDirection = iota // Children placed left to right
+	LayoutVertical                          // Children placed top to bottom
+)
+
+// LayoutItem is a child of a BoxLayout together with its sizing constraints
+// along the layout's main axis. On the cross axis a child fills the layout.
+type LayoutItem struct {
+	Widget Widget
+	Fixed  int    // If > 0, the exact size of the child
+	Min    int    // Minimum size
+	Max    int    // Maximum size, 0 for no limit
+	Flex   int    // Weight when sharing the remaining space, 0 counts as 1
+	Margin Insets // Space kept free around the child
+}
+
+// sizeSpec holds the constraints of one child or grid track along an axis
+type sizeSpec struct {
+	fixed int
+	min   int
+	max   int
+	flex  int
+}
+
+// distribute splits total cells between specs. Fixed sizes are honoured first,
+// then the remainder is shared by flex weight while respecting min and max.
+func distribute(total int, specs []sizeSpec) []int {
+	sizes := make([]int, len(specs))
+	frozen := make([]bool, len(specs))
+	remaining := total
+
+	for i, sp := range specs {
+		if sp.fixed > 0 {
+			sizes[i] = clampSize(sp.fixed, sp)
+			frozen[i] = true
+			remaining -= sizes[i]
+		}
+	}
+
+	// Share out the rest, freezing children that hit a bound and retrying
+	for {
+		weight := 0
+		for i, sp := range specs {
+			if !frozen[i] {
+				weight += max(sp.flex, 1)
+			}
+		}
+		if weight == 0 {
+			break
+		}
+
+		changed := false
+		share := max(remaining, 0)
+		for i, sp := range specs {
+			if frozen[i] {
+				continue
+			}
+			want := share * max(sp.flex, 1) / weight
+			if got := clampSize(want, sp); got != want {
+				sizes[i] = got
+				frozen[i] = true
+				remaining -= got
+				changed = true
+			}
+		}
+		if changed {
+			continue
+		}
+
+		// No bounds were hit: assign the shares, handing rounding leftovers
+		// to the first flexible children
+		used := 0
+		last := -1
+		for i, sp := range specs {
+			if !frozen[i] {
+				sizes[i] = share * max(sp.flex, 1) / weight
+				used += sizes[i]
+				last = i
+			}
+		}
+		for i := 0; used < share && i <= last; i++ {
+			if !frozen[i] && (specs[i].max == 0 || sizes[i] < specs[i].max) {
+				sizes[i]++
+				used++
+			}
+		}
+		break
+	}
+	return sizes
+}
+
+// clampSize limits size to the spec's min and max
+func clampSize(size int, sp sizeSpec) int {
+	if sp.max > 0 && size > sp.max {
+		size = sp.max
+	}
+	if size < sp.min {
+		size = sp.min
+	}
+	return max(size, 0)
+}
+
+// BoxLayout arranges child widgets in a row or column. Child geometry is
+// recomputed on every draw, so the layout follows terminal resizes.
+type BoxLayout struct {
+	Direction LayoutDirection
+	Items     []LayoutItem
+	Padding   Insets
+	Spacing   int // Cells between adjacent children
+	Focus     int // Index of the child that receives keyboard events
+
+	rects []Rect // Child geometry from the last layout
+}
+
+// NewBoxLayout creates an empty box layout
+func NewBoxLayout(direction LayoutDirection) *BoxLayout {
+	return &BoxLayout{Direction: direction}
+}
+
+// Add appends a child with the given constraints
+func (b *BoxLayout) Add(item LayoutItem) {
+	b.Items = append(b.Items, item)
+}
+
+// Layout computes the rectangles of the children within the given area
+func (b *BoxLayout) Layout(x, y, width, height int) []Rect {
+	area := Rect{X: x, Y: y, Width: width, Height: height}.Inset(b.Padding)
+
+	mainSize := area.Width
+	if b.Direction == LayoutVertical {
+		mainSize = area.Height
+	}
+	mainSize -= b.Spacing * max(len(b.Items)-1, 0)
+
+	// Margins along the main axis are outside the child's share
+	specs := make([]sizeSpec, len(b.Items))
+	for i, item := range b.Items {
+		lead, trail := item.Margin.Left, item.Margin.Right
+		if b.Direction == LayoutVertical {
+			lead, trail = item.Margin.Top, item.Margin.Bottom
+		}
+		mainSize -= lead + trail
+		specs[i] = sizeSpec{fixed: item.Fixed, min: item.Min, max: item.Max, flex: item.Flex}
+	}
+	sizes := distribute(mainSize, specs)
+
+	b.rects = b.rects[:0]
+	pos := area.X
+	if b.Direction == LayoutVertical {
+		pos = area.Y
+	}
+	for i, item := range b.Items {
+		m := item.Margin
+		var r Rect
+		if b.Direction == LayoutHorizontal {
+			pos += m.Left
+			r = Rect{X: pos, Y: area.Y + m.Top, Width: sizes[i], Height: max(0, area.Height-m.Top-m.Bottom)}
+			pos += sizes[i] + m.Right + b.Spacing
+		} else {
+			pos += m.Top
+			r = Rect{X: area.X + m.Left, Y: pos, Width: max(0, area.Width-m.Left-m.Right), Height: sizes[i]}
+			pos += sizes[i] + m.Bottom + b.Spacing
+		}
+		b.rects = append(b.rects, r)
+	}
+	return b.rects
+}
+
+// Draw lays out and draws the children
+func (b *BoxLayout) Draw(s tcell.Screen, x, y, width, height int) {
+	rects := b.Layout(x, y, width, height)
+	for i, item := range b.Items {
+		if item.Widget != nil && rects[i].Width > 0 && rects[i].Height > 0 {
+			item.Widget.Draw(s, rects[i].X, rects[i].Y, rects[i].Width, rects[i].Height)
+		}
+	}
+}
+
+// HandleEvent routes mouse events to the child under the pointer and key
+// events to the focused child. Clicking a child focuses it.
+func (b *BoxLayout) HandleEvent(ev tcell.Event) bool {
+	widgets := make([]Widget, len(b.Items))
+	for i, item := range b.Items {
+		widgets[i] = item.Widget
+	}
+	return routeEvent(ev, widgets, b.rects, &b.Focus)
+}
+
+// routeEvent delivers ev to one of the widgets laid out in rects
+func routeEvent(ev tcell.Event, widgets []Widget, rects []Rect, focus *int) bool {
+	if e, ok := ev.(*tcell.EventMouse); ok {
+		mouseX, mouseY := e.Position()
+		for i, r := range rects {
+			if i < len(widgets) && widgets[i] != nil && r.Contains(mouseX, mouseY) {
+				if e.Buttons() == tcell.ButtonPrimary {
+					*focus = i
+				}
+				return widgets[i].HandleEvent(ev)
+			}
+		}
+		return false
+	}
+	if *focus >= 0 && *focus < len(widgets) && widgets[*focus] != nil {
+		return widgets[*focus].HandleEvent(ev)
+	}
+	return false
+}
+
+// GridTrack describes the size of a grid row or column
+type GridTrack struct {
+	Fixed int // If > 0, the exact size of the track
+	Min   int // Minimum size
+	Max   int // Maximum size, 0 for no limit
+	Flex  int // Weight when sharing the remaining space, 0 counts as 1
+}
+
+// GridCell places a widget in a GridLayout, optionally spanning several tracks
+type GridCell struct {
+	Widget  Widget
+	Row     int
+	Col     int
+	RowSpan int // 0 counts as 1
+	ColSpan int // 0 counts as 1
+	Margin  Insets
+}
+
+// GridLayout arranges child widgets in rows and columns. Track sizes are
+// recomputed on every draw, so the grid follows terminal resizes.
+type GridLayout struct {
+	Rows    []GridTrack
+	Columns []GridTrack
+	Cells   []GridCell
+	Padding Insets
+	Spacing int // Cells between adjacent rows and columns
+	Focus   int // Index of the cell that receives keyboard events
+
+	rects []Rect // Cell geometry from the last layout
+}
+
+// NewGridLayout creates a grid with the given number of equally flexible rows and columns
+func NewGridLayout(rows, columns int) *GridLayout {
+	return &GridLayout{
+		Rows:    make([]GridTrack, rows),
+		Columns: make([]GridTrack, columns),
+	}
+}
+
+// Add places a widget in the grid
+func (g *GridLayout) Add(cell GridCell) {
+	g.Cells = append(g.Cells, cell)
+}
+
+// trackPositions returns the start offset and size of each track
+func trackPositions(start, total, spacing int, tracks []GridTrack) (starts, sizes []int) {
+	specs := make([]sizeSpec, len(tracks))
+	for i, t := range tracks {
+		specs[i] = sizeSpec{fixed: t.Fixed, min: t.Min, max: t.Max, flex: t.Flex}
+	}
+	sizes = distribute(total-spacing*max(len(tracks)-1, 0), specs)
+	starts = make([]int, len(tracks))
+	pos := start
+	for i := range tracks {
+		starts[i] = pos
+		pos += sizes[i] + spacing
+	}
+	return starts, sizes
+}
+
+// Layout computes the rectangles of the cells within the given area
+func (g *GridLayout) Layout(x, y, width, height int) []Rect {
+	area := Rect{X: x, Y: y, Width: width, Height: height}.Inset(g.Padding)
+	colStarts, colSizes := trackPositions(area.X, area.Width, g.Spacing, g.Columns)
+	rowStarts, rowSizes := trackPositions(area.Y, area.Height, g.Spacing, g.Rows)
+
+	g.rects = g.rects[:0]
+	for _, cell := range g.Cells {
+		g.rects = append(g.rects, spanRect(cell, colStarts, colSizes, rowStarts, rowSizes).Inset(cell.Margin))
+	}
+	return g.rects
+}
+
+// spanRect returns the area covered by a cell, including the spacing between spanned tracks
+func spanRect(cell GridCell, colStarts, colSizes, rowStarts, rowSizes []int) Rect {
+	colEnd := min(cell.Col+max(cell.ColSpan, 1), len(colStarts)) - 1
+	rowEnd := min(cell.Row+max(cell.RowSpan, 1), len(rowStarts)) - 1
+	if cell.Col < 0 || cell.Row < 0 || cell.Col > colEnd || cell.Row > rowEnd {
+		return Rect{}
+	}
+	return Rect{
+		X:      colStarts[cell.Col],
+		Y:      rowStarts[cell.Row],
+		Width:  colStarts[colEnd] + colSizes[colEnd] - colStarts[cell.Col],
+		Height: rowStarts[rowEnd] + rowSizes[rowEnd] - rowStarts[cell.Row],
+	}
+}
+
+// Draw lays out and draws the cells
+func (g *GridLayout) Draw(s tcell.Screen, x, y, width, height int) {
+	rects := g.Layout(x, y, width, height)
+	for i, cell := range g.Cells {
+		if cell.Widget != nil && rects[i].Width > 0 && rects[i].Height > 0 {
+			cell.Widget.Draw(s, rects[i].X, rects[i].Y, rects[i].Width, rects[i].Height)
+		}
+	}
+}
+
+// HandleEvent routes mouse events to the cell under the pointer and key
+// events to the focused cell. Clicking a cell focuses it.
+func (g *GridLayout) HandleEvent(ev tcell.Event) bool {
+	widgets := make([]Widget, len(g.Cells))
+	for i, cell := range g.Cells {
+		widgets[i] = cell.Widget
+	}
+	return routeEvent(ev, widgets, g.rects, &g.Focus)
+}
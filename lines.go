@@ -0,0 +1,301 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// LineWeight is the kind of line an arm of a box-drawing character has
+type LineWeight uint8
+
+const (
+	LineNone LineWeight = iota
+	LineSingle
+	LineDouble
+	LineHeavy
+)
+
+// LineArms describes a box-drawing character by the lines leaving its cell
+type LineArms struct {
+	Up, Down, Left, Right LineWeight
+}
+
+// lineGlyphs maps the box-drawing characters to their arms
+var lineGlyphs = map[rune]LineArms{
+	'─': {LineNone, LineNone, LineSingle, LineSingle},
+	'━': {LineNone, LineNone, LineHeavy, LineHeavy},
+	'│': {LineSingle, LineSingle, LineNone, LineNone},
+	'┃': {LineHeavy, LineHeavy, LineNone, LineNone},
+	'┌': {LineNone, LineSingle, LineNone, LineSingle},
+	'┍': {LineNone, LineSingle, LineNone, LineHeavy},
+	'┎': {LineNone, LineHeavy, LineNone, LineSingle},
+	'┏': {LineNone, LineHeavy, LineNone, LineHeavy},
+	'┐': {LineNone, LineSingle, LineSingle, LineNone},
+	'┑': {LineNone, LineSingle, LineHeavy, LineNone},
+	'┒': {LineNone, LineHeavy, LineSingle, LineNone},
+	'┓': {LineNone, LineHeavy, LineHeavy, LineNone},
+	'└': {LineSingle, LineNone, LineNone, LineSingle},
+	'┕': {LineSingle, LineNone, LineNone, LineHeavy},
+	'┖': {LineHeavy, LineNone, LineNone, LineSingle},
+	'┗': {LineHeavy, LineNone, LineNone, LineHeavy},
+	'┘': {LineSingle, LineNone, LineSingle, LineNone},
+	'┙': {LineSingle, LineNone, LineHeavy, LineNone},
+	'┚': {LineHeavy, LineNone, LineSingle, LineNone},
+	'┛': {LineHeavy, LineNone, LineHeavy, LineNone},
+	'├': {LineSingle, LineSingle, LineNone, LineSingle},
+	'┝': {LineSingle, LineSingle, LineNone, LineHeavy},
+	'┞': {LineHeavy, LineSingle, LineNone, LineSingle},
+	'┟': {LineSingle, LineHeavy, LineNone, LineSingle},
+	'┠': {LineHeavy, LineHeavy, LineNone, LineSingle},
+	'┡': {LineHeavy, LineSingle, LineNone, LineHeavy},
+	'┢': {LineSingle, LineHeavy, LineNone, LineHeavy},
+	'┣': {LineHeavy, LineHeavy, LineNone, LineHeavy},
+	'┤': {LineSingle, LineSingle, LineSingle, LineNone},
+	'┥': {LineSingle, LineSingle, LineHeavy, LineNone},
+	'┦': {LineHeavy, LineSingle, LineSingle, LineNone},
+	'┧': {LineSingle, LineHeavy, LineSingle, LineNone},
+	'┨': {LineHeavy, LineHeavy, LineSingle, LineNone},
+	'┩': {LineHeavy, LineSingle, LineHeavy, LineNone},
+	'┪': {LineSingle, LineHeavy, LineHeavy, LineNone},
+	'┫': {LineHeavy, LineHeavy, LineHeavy, LineNone},
+	'┬': {LineNone, LineSingle, LineSingle, LineSingle},
+	'┭': {LineNone, LineSingle, LineHeavy, LineSingle},
+	'┮': {LineNone, LineSingle, LineSingle, LineHeavy},
+	'┯': {LineNone, LineSingle, LineHeavy, LineHeavy},
+	'┰': {LineNone, LineHeavy, LineSingle, LineSingle},
+	'┱': {LineNone, LineHeavy, LineHeavy, LineSingle},
+	'┲': {LineNone, LineHeavy, LineSingle, LineHeavy},
+	'┳': {LineNone, LineHeavy, LineHeavy, LineHeavy},
+	'┴': {LineSingle, LineNone, LineSingle, LineSingle},
+	'┵': {LineSingle, LineNone, LineHeavy, LineSingle},
+	'┶': {LineSingle, LineNone, LineSingle, LineHeavy},
+	'┷': {LineSingle, LineNone, LineHeavy, LineHeavy},
+	'┸': {LineHeavy, LineNone, LineSingle, LineSingle},
+	'┹': {LineHeavy, LineNone, LineHeavy, LineSingle},
+	'┺': {LineHeavy, LineNone, LineSingle, LineHeavy},
+	'┻': {LineHeavy, LineNone, LineHeavy, LineHeavy},
+	'┼': {LineSingle, LineSingle, LineSingle, LineSingle},
+	'┽': {LineSingle, LineSingle, LineHeavy, LineSingle},
+	'┾': {LineSingle, LineSingle, LineSingle, LineHeavy},
+	'┿': {LineSingle, LineSingle, LineHeavy, LineHeavy},
+	'╀': {LineHeavy, LineSingle, LineSingle, LineSingle},
+	'╁': {LineSingle, LineHeavy, LineSingle, LineSingle},
+	'╂': {LineHeavy, LineHeavy, LineSingle, LineSingle},
+	'╃': {LineHeavy, LineSingle, LineHeavy, LineSingle},
+	'╄': {LineHeavy, LineSingle, LineSingle, LineHeavy},
+	'╅': {LineSingle, LineHeavy, LineHeavy, LineSingle},
+	'╆': {LineSingle, LineHeavy, LineSingle, LineHeavy},
+	'╇': {LineHeavy, LineSingle, LineHeavy, LineHeavy},
+	'╈': {LineSingle, LineHeavy, LineHeavy, LineHeavy},
+	'╉': {LineHeavy, LineHeavy, LineHeavy, LineSingle},
+	'╊': {LineHeavy, LineHeavy, LineSingle, LineHeavy},
+	'╋': {LineHeavy, LineHeavy, LineHeavy, LineHeavy},
+	'═': {LineNone, LineNone, LineDouble, LineDouble},
+	'║': {LineDouble, LineDouble, LineNone, LineNone},
+	'╒': {LineNone, LineSingle, LineNone, LineDouble},
+	'╓': {LineNone, LineDouble, LineNone, LineSingle},
+	'╔': {LineNone, LineDouble, LineNone, LineDouble},
+	'╕': {LineNone, LineSingle, LineDouble, LineNone},
+	'╖': {LineNone, LineDouble, LineSingle, LineNone},
+	'╗': {LineNone, LineDouble, LineDouble, LineNone},
+	'╘': {LineSingle, LineNone, LineNone, LineDouble},
+	'╙': {LineDouble, LineNone, LineNone, LineSingle},
+	'╚': {LineDouble, LineNone, LineNone, LineDouble},
+	'╛': {LineSingle, LineNone, LineDouble, LineNone},
+	'╜': {LineDouble, LineNone, LineSingle, LineNone},
+	'╝': {LineDouble, LineNone, LineDouble, LineNone},
+	'╞': {LineSingle, LineSingle, LineNone, LineDouble},
+	'╟': {LineDouble, LineDouble, LineNone, LineSingle},
+	'╠': {LineDouble, LineDouble, LineNone, LineDouble},
+	'╡': {LineSingle, LineSingle, LineDouble, LineNone},
+	'╢': {LineDouble, LineDouble, LineSingle, LineNone},
+	'╣': {LineDouble, LineDouble, LineDouble, LineNone},
+	'╤': {LineNone, LineSingle, LineDouble, LineDouble},
+	'╥': {LineNone, LineDouble, LineSingle, LineSingle},
+	'╦': {LineNone, LineDouble, LineDouble, LineDouble},
+	'╧': {LineSingle, LineNone, LineDouble, LineDouble},
+	'╨': {LineDouble, LineNone, LineSingle, LineSingle},
+	'╩': {LineDouble, LineNone, LineDouble, LineDouble},
+	'╪': {LineSingle, LineSingle, LineDouble, LineDouble},
+	'╫': {LineDouble, LineDouble, LineSingle, LineSingle},
+	'╬': {LineDouble, LineDouble, LineDouble, LineDouble},
+	'╭': {LineNone, LineSingle, LineNone, LineSingle},
+	'╮': {LineNone, LineSingle, LineSingle, LineNone},
+	'╯': {LineSingle, LineNone, LineSingle, LineNone},
+	'╰': {LineSingle, LineNone, LineNone, LineSingle},
+	'╴': {LineNone, LineNone, LineSingle, LineNone},
+	'╵': {LineSingle, LineNone, LineNone, LineNone},
+	'╶': {LineNone, LineNone, LineNone, LineSingle},
+	'╷': {LineNone, LineSingle, LineNone, LineNone},
+	'╸': {LineNone, LineNone, LineHeavy, LineNone},
+	'╹': {LineHeavy, LineNone, LineNone, LineNone},
+	'╺': {LineNone, LineNone, LineNone, LineHeavy},
+	'╻': {LineNone, LineHeavy, LineNone, LineNone},
+	'╼': {LineNone, LineNone, LineSingle, LineHeavy},
+	'╽': {LineSingle, LineHeavy, LineNone, LineNone},
+	'╾': {LineNone, LineNone, LineHeavy, LineSingle},
+	'╿': {LineHeavy, LineSingle, LineNone, LineNone},
+}
+
+// armGlyphs maps arms back to characters. The rounded corners are left out,
+// so junctions resolve to the square ones.
+var armGlyphs = func() map[LineArms]rune {
+	glyphs := make(map[LineArms]rune, len(lineGlyphs))
+	for r, arms := range lineGlyphs {
+		switch r {
+		case '╭', '╮', '╯', '╰':
+			continue
+		}
+		glyphs[arms] = r
+	}
+	return glyphs
+}()
+
+// ArmsOf returns the arms of a box-drawing character
+func ArmsOf(r rune) (LineArms, bool) {
+	arms, ok := lineGlyphs[r]
+	return arms, ok
+}
+
+// RuneFor returns the box-drawing character with the given arms. Unicode has
+// no characters mixing double with heavy lines, or double with single lines
+// on the same axis; those fall back to single lines for the heavy arms, then
+// the double ones.
+func RuneFor(arms LineArms) (rune, bool) {
+	for _, a := range []LineArms{
+		arms,
+		arms.replace(LineHeavy, LineSingle),
+		arms.replace(LineDouble, LineSingle),
+		arms.replace(LineHeavy, LineSingle).replace(LineDouble, LineSingle),
+	} {
+		if r, ok := armGlyphs[a]; ok {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// replace returns the arms with every arm of weight from changed to to
+func (a LineArms) replace(from, to LineWeight) LineArms {
+	for _, arm := range []*LineWeight{&a.Up, &a.Down, &a.Left, &a.Right} {
+		if *arm == from {
+			*arm = to
+		}
+	}
+	return a
+}
+
+// merge returns the arms of both, keeping the weight of a where both have an arm
+func (a LineArms) merge(o LineArms) LineArms {
+	pick := func(mine, theirs LineWeight) LineWeight {
+		if mine != LineNone {
+			return mine
+		}
+		return theirs
+	}
+	return LineArms{pick(a.Up, o.Up), pick(a.Down, o.Down), pick(a.Left, o.Left), pick(a.Right, o.Right)}
+}
+
+// MergeRune returns the character for drawing r over existing: where both are
+// box-drawing characters the junction joining their lines, otherwise r. Lines
+// already drawn keep their weight, so a single line meeting a double one
+// forms ╟, ╤ and the like.
+func MergeRune(existing, r rune) rune {
+	under, ok := lineGlyphs[existing]
+	if !ok {
+		return r
+	}
+	over, ok := lineGlyphs[r]
+	if !ok {
+		return r
+	}
+	switch arms := under.merge(over); arms {
+	case under:
+		return existing // Nothing to add, so keep e.g. a rounded corner
+	case over:
+		return r
+	default:
+		if merged, ok := RuneFor(arms); ok {
+			return merged
+		}
+	}
+	return r
+}
+
+// lineCell is a cell of a LineSet
+type lineCell struct {
+	arms  LineArms
+	style tcell.Style
+}
+
+// LineSet collects horizontal and vertical lines and draws them with the
+// proper junctions where they meet, like the line tools of the classic form
+// designers. Boxes, split panes and table grids drawn into one set share
+// their edges.
+type LineSet struct {
+	cells map[[2]int]lineCell
+}
+
+// NewLineSet creates an empty line set
+func NewLineSet() *LineSet {
+	return &LineSet{cells: make(map[[2]int]lineCell)}
+}
+
+// add gives the cell at (x, y) the arms of a; arms the cell already has keep
+// their weight
+func (l *LineSet) add(x, y int, a LineArms, style tcell.Style) {
+	c := l.cells[[2]int{x, y}]
+	l.cells[[2]int{x, y}] = lineCell{arms: c.arms.merge(a), style: style}
+}
+
+// HLine adds a horizontal line of length cells from (x, y)
+func (l *LineSet) HLine(x, y, length int, weight LineWeight, style tcell.Style) {
+	for i := 0; i < length; i++ {
+		var a LineArms
+		if i > 0 {
+			a.Left = weight
+		}
+		if i < length-1 {
+			a.Right = weight
+		}
+		l.add(x+i, y, a, style)
+	}
+}
+
+// VLine adds a vertical line of length cells from (x, y)
+func (l *LineSet) VLine(x, y, length int, weight LineWeight, style tcell.Style) {
+	for j := 0; j < length; j++ {
+		var a LineArms
+		if j > 0 {
+			a.Up = weight
+		}
+		if j < length-1 {
+			a.Down = weight
+		}
+		l.add(x, y+j, a, style)
+	}
+}
+
+// Box adds the outline of a box, with horizontal edges of one weight and
+// vertical edges of another
+func (l *LineSet) Box(x, y, w, h int, horizontal, vertical LineWeight, style tcell.Style) {
+	if w < 2 || h < 2 {
+		return
+	}
+	l.HLine(x, y, w, horizontal, style)
+	l.HLine(x, y+h-1, w, horizontal, style)
+	l.VLine(x, y, h, vertical, style)
+	l.VLine(x+w-1, y, h, vertical, style)
+}
+
+// Draw draws the lines onto the surface, clipped to it
+func (l *LineSet) Draw(s Surface) {
+	sw, sh := s.Size()
+	for p, c := range l.cells {
+		if p[0] < 0 || p[1] < 0 || p[0] >= sw || p[1] >= sh {
+			continue
+		}
+		if r, ok := RuneFor(c.arms); ok {
+			s.SetContent(p[0], p[1], r, nil, c.style)
+		}
+	}
+}
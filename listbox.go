@@ -0,0 +1,225 @@
+package retrotui
+
+import (
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ListBox is a scrolling list of text items. With a DragManager set, items
+// can be dragged out of the list and dropped into lists accepting their type.
+type ListBox struct {
+	Items      []string
+	Selected   int // Index of the selected item, -1 for none
+	Focused    bool
+	OnSelect   func(index int)                     // Called when an item is chosen with Enter or a double click
+	Drag       *DragManager                        // Enables dragging items out of the list
+	DragType   string                              // Payload type of dragged items
+	MoveOnDrop bool                                // Remove an item from this list once it is dropped elsewhere
+	Accepts    []string                            // Payload types that may be dropped on the list
+	OnDrop     func(p DragPayload, index int) bool // Handles a drop; by default the label is inserted
+	Keymap     *Keymap                             // Navigation keys; scope WidgetScope("list")
+
+	Fg         tcell.Color
+	Bg         tcell.Color
+	SelectedFg tcell.Color
+	SelectedBg tcell.Color
+
+	scroll  int  // First visible item
+	area    Rect // Geometry from the last draw, used for mouse hit testing
+	dropped int  // Index of the last item dropped on the list
+}
+
+// NewListBox creates a list box with the classic colors
+func NewListBox(items []string) *ListBox {
+	l := &ListBox{
+		Items:      items,
+		Selected:   -1,
+		DragType:   "text",
+		Fg:         tcell.ColorWhite,
+		Bg:         tcell.ColorBlue,
+		SelectedFg: tcell.ColorBlack,
+		SelectedBg: tcell.ColorTeal,
+	}
+	if len(items) > 0 {
+		l.Selected = 0
+	}
+	return l
+}
+
+// Draw renders the visible items into the rectangle
+func (l *ListBox) Draw(s tcell.Screen, x, y, width, height int) {
+	l.area = Rect{X: x, Y: y, Width: width, Height: height}
+	l.ensureVisible()
+
+	fill := DrawOptions{FillRune: ' '}
+	FillBox(s, x, y, width, height, l.Bg, fill)
+	for row := 0; row < height && l.scroll+row < len(l.Items); row++ {
+		index := l.scroll + row
+		style := tcell.StyleDefault.Foreground(l.Fg).Background(l.Bg)
+		if index == l.Selected {
+			style = tcell.StyleDefault.Foreground(l.SelectedFg).Background(l.SelectedBg)
+			for i := 0; i < width; i++ {
+				s.SetContent(x+i, y+row, ' ', nil, style)
+			}
+		}
+		text := []rune(l.Items[index])
+		if len(text) > width-1 {
+			text = text[:max(0, width-1)]
+		}
+		PrintAt(s, x+1, y+row, string(text), style)
+	}
+}
+
+// ensureVisible scrolls so the selected item is visible
+func (l *ListBox) ensureVisible() {
+	if l.Selected >= 0 {
+		if l.Selected < l.scroll {
+			l.scroll = l.Selected
+		} else if l.area.Height > 0 && l.Selected >= l.scroll+l.area.Height {
+			l.scroll = l.Selected - l.area.Height + 1
+		}
+	}
+	l.scroll = max(0, min(l.scroll, len(l.Items)-l.area.Height))
+}
+
+// indexAt returns the item at screen row y, or -1
+func (l *ListBox) indexAt(y int) int {
+	index := l.scroll + y - l.area.Y
+	if index < 0 || index >= len(l.Items) || y >= l.area.Y+l.area.Height {
+		return -1
+	}
+	return index
+}
+
+// selectIndex selects the item at index, clamped to the list
+func (l *ListBox) selectIndex(index int) {
+	if len(l.Items) == 0 {
+		l.Selected = -1
+		return
+	}
+	l.Selected = max(0, min(index, len(l.Items)-1))
+	l.ensureVisible()
+}
+
+// HandleEvent processes keys while focused and mouse clicks on the list
+func (l *ListBox) HandleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		if !l.Focused {
+			return false
+		}
+		var consumed bool
+		if e, consumed = l.Keymap.translate(e, WidgetScope("list")); consumed {
+			return true
+		}
+		switch e.Key() {
+		case tcell.KeyUp:
+			l.selectIndex(l.Selected - 1)
+		case tcell.KeyDown:
+			l.selectIndex(l.Selected + 1)
+		case tcell.KeyPgUp:
+			l.selectIndex(l.Selected - max(1, l.area.Height-1))
+		case tcell.KeyPgDn:
+			l.selectIndex(l.Selected + max(1, l.area.Height-1))
+		case tcell.KeyHome:
+			l.selectIndex(0)
+		case tcell.KeyEnd:
+			l.selectIndex(len(l.Items) - 1)
+		case tcell.KeyEnter:
+			if l.OnSelect != nil && l.Selected >= 0 {
+				l.OnSelect(l.Selected)
+			}
+		default:
+			return false
+		}
+		return true
+
+	case *tcell.EventMouse:
+		x, y := e.Position()
+		if e.Buttons() != tcell.ButtonPrimary || !l.area.Contains(x, y) {
+			return false
+		}
+		l.Focused = true
+		if index := l.indexAt(y); index >= 0 {
+			l.Selected = index
+		}
+		return true
+	}
+	return false
+}
+
+// HitTest reports whether (x, y) lies within the list
+func (l *ListBox) HitTest(s tcell.Screen, x, y int) bool {
+	return l.area.Contains(x, y)
+}
+
+// HandleMouse implements MouseTarget: presses select, double clicks choose,
+// the wheel scrolls and dragging an item starts a drag
+func (l *ListBox) HandleMouse(s tcell.Screen, ev *EventMouseAction) bool {
+	switch ev.Action {
+	case MousePress:
+		return l.HandleEvent(ev.Raw)
+	case MouseDoubleClick:
+		if index := l.indexAt(ev.Y); index >= 0 && l.OnSelect != nil {
+			l.OnSelect(index)
+		}
+		return true
+	case MouseWheelUp:
+		l.scroll = max(0, l.scroll-1)
+		return true
+	case MouseWheelDown:
+		l.scroll = max(0, min(l.scroll+1, len(l.Items)-l.area.Height))
+		return true
+	case MouseDragStart:
+		index := l.indexAt(ev.StartY)
+		if l.Drag == nil || index < 0 {
+			return false
+		}
+		l.Drag.Start(DragPayload{Type: l.DragType, Data: index, Label: l.Items[index]}, l, ev.X, ev.Y)
+		return true
+	}
+	return false
+}
+
+// AcceptsDrop implements DropTarget: the list accepts the types in Accepts
+func (l *ListBox) AcceptsDrop(p DragPayload, x, y int) bool {
+	return slices.Contains(l.Accepts, p.Type)
+}
+
+// Drop implements DropTarget, inserting the payload before the item at (x, y)
+func (l *ListBox) Drop(s tcell.Screen, p DragPayload, x, y int) bool {
+	index := l.indexAt(y)
+	if index < 0 {
+		index = len(l.Items)
+	}
+	if l.OnDrop != nil {
+		return l.OnDrop(p, index)
+	}
+	l.Items = slices.Insert(l.Items, index, p.Label)
+	l.Selected = index
+	l.dropped = index
+	return true
+}
+
+// DragEnded implements DragSource: with MoveOnDrop a dropped item is removed,
+// so dropping within the same list reorders it
+func (l *ListBox) DragEnded(p DragPayload, target DropTarget, dropped bool) {
+	index, ok := p.Data.(int)
+	if !dropped || !l.MoveOnDrop || !ok {
+		return
+	}
+	if target == DropTarget(l) && l.OnDrop == nil {
+		// The copy was inserted first, possibly before the original
+		if l.dropped <= index {
+			index++
+		} else {
+			l.Selected--
+		}
+	}
+	if index >= len(l.Items) {
+		return
+	}
+	l.Items = slices.Delete(l.Items, index, index+1)
+	l.Selected = min(l.Selected, len(l.Items)-1)
+}
@@ -0,0 +1,41 @@
+package retrotui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestListBoxKeymap(t *testing.T) {
+	key := func(k tcell.Key) *tcell.EventKey { return tcell.NewEventKey(k, 0, tcell.ModNone) }
+	char := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+	tests := []struct {
+		preset KeyPreset
+		keys   []*tcell.EventKey
+		want   int
+	}{
+		{PresetDefault, []*tcell.EventKey{key(tcell.KeyDown), key(tcell.KeyDown)}, 2},
+		{PresetDefault, []*tcell.EventKey{char('j')}, 0},
+		{PresetVim, []*tcell.EventKey{char('j'), char('j'), char('k')}, 1},
+		{PresetVim, []*tcell.EventKey{char('G'), char('g'), char('g')}, 0},
+		{PresetVim, []*tcell.EventKey{char('G')}, 4},
+		{PresetWordStar, []*tcell.EventKey{key(tcell.KeyCtrlX), key(tcell.KeyCtrlX), key(tcell.KeyCtrlE)}, 1},
+		{PresetWordStar, []*tcell.EventKey{key(tcell.KeyCtrlQ), char('C')}, 4},
+		{PresetEmacs, []*tcell.EventKey{key(tcell.KeyCtrlN), key(tcell.KeyCtrlN)}, 2},
+	}
+	for _, tt := range tests {
+		m := NewKeymap()
+		if err := m.UsePreset(tt.preset); err != nil {
+			t.Fatal(err)
+		}
+		l := NewListBox([]string{"a", "b", "c", "d", "e"})
+		l.Keymap = m
+		l.Focused = true
+		for _, e := range tt.keys {
+			l.HandleEvent(e)
+		}
+		if l.Selected != tt.want {
+			t.Errorf("%s: selected %d, want %d", tt.preset, l.Selected, tt.want)
+		}
+	}
+}
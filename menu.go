@@ -2,12 +2,13 @@ package retrotui
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/gdamore/tcell/v2"
 )
 
 // DrawMenuBar draws the menu bar at the top of the screen
-func DrawMenuBar(s tcell.Screen, menus []Menu, activeMenu int, menuBarActive bool, menuBarFg, menuBarBg, menuActiveFg, menuActiveBg tcell.Color) {
+func DrawMenuBar(s Surface, menus []Menu, activeMenu int, menuBarActive bool, menuBarFg, menuBarBg, menuActiveFg, menuActiveBg tcell.Color) {
 	width, _ := s.Size()
 	menuBarHeight := 1
 	menuBarY := 0
@@ -31,48 +32,135 @@ func DrawMenuBar(s tcell.Screen, menus []Menu, activeMenu int, menuBarActive boo
 			menuStyle = tcell.StyleDefault.Foreground(menuBarFg).Background(menuBarBg)
 		}
 
-		// Determine position based on alignment
-		menuX := menu.Position
-		if menu.Align { // Right aligned
-			menuX = width - len(menu.Title) - 2
-		}
-
 		// Draw the menu title with hotkey highlighted
-		PrintMenuTitle(s, menuX, menuBarY, menu.Title, menu.HotKey, menuStyle)
+		PrintMenuTitle(s, titleX(menus, i, width), menuBarY, menu.Title, menu.HotKey, menuStyle)
 	}
 }
 
-// DrawDropdownMenu draws a dropdown menu under a menu bar item
-func DrawDropdownMenu(s tcell.Screen, menu Menu, activeMenuItem int, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor tcell.Color) {
-	items := menu.Items
+// titleX returns the column of the title of menus[i]: its Position, or for a
+// right-aligned menu that was never laid out, its place at the right edge
+// as MenuBar.Layout would compute it
+func titleX(menus []Menu, i, width int) int {
+	if !menus[i].Align || menus[i].Position > 0 {
+		return menus[i].Position
+	}
+	x := width
+	for j := len(menus) - 1; j >= i; j-- {
+		if menus[j].Align {
+			x -= len(menus[j].Title) + 2
+		}
+	}
+	return x
+}
 
-	// Find the widest menu item
+// menuBox describes one open dropdown or submenu box
+type menuBox struct {
+	x, y, width, height int
+	items               []DropdownItem
+	active              int
+}
+
+// dropdownWidth returns the box width needed to show items, including room
+// for the submenu indicator
+func dropdownWidth(items []DropdownItem) int {
 	maxWidth := 0
 	for _, item := range items {
-		if len(item.Text) > maxWidth {
-			maxWidth = len(item.Text)
+		if item.Hidden {
+			continue
+		}
+		itemWidth := len(item.Text)
+		if item.Shortcut != "" {
+			itemWidth += 2 + len(item.Shortcut)
+		}
+		if len(item.Items) > 0 {
+			itemWidth += 2
+		}
+		if itemWidth > maxWidth {
+			maxWidth = itemWidth
 		}
 	}
 
 	// Add padding
-	menuWidth := maxWidth + 4
-	menuHeight := len(items) + 2
+	return maxWidth + 4
+}
+
+// DropdownBounds returns the position and size of the dropdown box of a menu
+func DropdownBounds(s Surface, menu Menu) (x, y, width, height int) {
+	menuWidth := dropdownWidth(menu.Items)
+	menuHeight := visibleCount(menu.Items) + 2
 
 	// Get position from the menu
 	menuX := menu.Position
 	menuY := 1 // Just below the menu bar
 
-	// Adjust for right-aligned menus
+	// Right-aligned menus line their right edge up with the end of their
+	// title; one that was never laid out hangs from the right edge
+	screenWidth, _ := s.Size()
 	if menu.Align {
-		menuX = menu.Position - menuWidth + len(menu.Title) + 2
+		if menu.Position > 0 {
+			menuX = menu.Position - menuWidth + len(menu.Title) + 2
+		} else {
+			menuX = screenWidth - menuWidth
+		}
 	}
 
 	// Ensure the menu stays within screen bounds
-	width, _ := s.Size()
-	if menuX+menuWidth > width {
-		menuX = width - menuWidth
+	if menuX+menuWidth > screenWidth {
+		menuX = screenWidth - menuWidth
+	}
+	menuX = max(0, menuX)
+
+	return menuX, menuY, menuWidth, menuHeight
+}
+
+// SubmenuBounds returns the position and size of the submenu opened from the
+// item shown on row index of a parent box at (parentX, parentY) of width
+// parentWidth.
+// The submenu opens to the right of its parent, or to the left when there is
+// not enough room on the right.
+func SubmenuBounds(s Surface, parentX, parentY, parentWidth, index int, items []DropdownItem) (x, y, width, height int) {
+	screenWidth, screenHeight := s.Size()
+	width = dropdownWidth(items)
+	height = visibleCount(items) + 2
+
+	// Line the first child up with the parent item
+	x = parentX + parentWidth - 1
+	y = parentY + index
+	if x+width > screenWidth && parentX-width+1 >= 0 {
+		x = parentX - width + 1
+	}
+	if y+height > screenHeight {
+		y = max(0, screenHeight-height)
+	}
+	return x, y, width, height
+}
+
+// cascadeBoxes returns the dropdown box of menu followed by the box of each
+// open submenu along submenuPath
+func cascadeBoxes(s Surface, menu Menu, activeMenuItem int, submenuPath []int) []menuBox {
+	x, y, w, h := DropdownBounds(s, menu)
+	return cascadeFrom(s, menuBox{x: x, y: y, width: w, height: h, items: menu.Items, active: activeMenuItem}, submenuPath)
+}
+
+// cascadeFrom returns root followed by the box of each open submenu along submenuPath
+func cascadeFrom(s Surface, root menuBox, submenuPath []int) []menuBox {
+	boxes := []menuBox{root}
+	var x, y, w, h int
+
+	for _, active := range submenuPath {
+		parent := boxes[len(boxes)-1]
+		if parent.active < 0 || parent.active >= len(parent.items) || len(parent.items[parent.active].Items) == 0 {
+			break
+		}
+		items := parent.items[parent.active].Items
+		x, y, w, h = SubmenuBounds(s, parent.x, parent.y, parent.width, itemRow(parent.items, parent.active), items)
+		boxes = append(boxes, menuBox{x: x, y: y, width: w, height: h, items: items, active: active})
 	}
+	return boxes
+}
 
+// drawMenuBox draws a dropdown or submenu box with its items
+func drawMenuBox(s Surface, box menuBox, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor tcell.Color) {
 	// Draw the menu box
 	menuOptions := DrawOptions{
 		FillPatternEnabled: false,
@@ -80,31 +168,219 @@ func DrawDropdownMenu(s tcell.Screen, menu Menu, activeMenuItem int, dropdownFg,
 		ShadowEnabled:      true,
 		DoubleLine:         false,
 	}
-	DrawBox(s, menuX, menuY, menuWidth, menuHeight, tcell.ColorBlack, dropdownBg, menuOptions)
+	DrawBox(s, box.x, box.y, box.width, box.height, tcell.ColorBlack, dropdownBg, menuOptions)
 
-	// Draw menu items
-	for i, item := range items {
-		itemY := menuY + 1 + i
+	// Draw menu items, leaving no gaps for hidden ones
+	row := 0
+	for i, item := range box.items {
+		if item.Hidden {
+			continue
+		}
+		itemY := box.y + 1 + row
+		row++
 		if item.IsSeparator {
 			// Draw a separator line
-			for j := 0; j < menuWidth-2; j++ {
-				s.SetContent(menuX+1+j, itemY, '─', nil, tcell.StyleDefault.Foreground(separatorColor).Background(dropdownBg))
+			for j := 0; j < box.width-2; j++ {
+				s.SetContent(box.x+1+j, itemY, '─', nil, tcell.StyleDefault.Foreground(separatorColor).Background(dropdownBg))
 			}
 		} else {
-			// Determine style based on whether this item is selected
+			// Determine style based on whether this item is selected;
+			// disabled items are greyed out using the separator color
 			itemStyle := tcell.StyleDefault.Foreground(dropdownFg).Background(dropdownBg)
-			if activeMenuItem == i {
+			if item.Disabled {
+				itemStyle = itemStyle.Foreground(separatorColor)
+			}
+			if box.active == i {
 				itemStyle = tcell.StyleDefault.Foreground(dropdownActiveFg).Background(dropdownActiveBg)
+				if item.Disabled {
+					itemStyle = itemStyle.Foreground(separatorColor)
+				}
+			}
+
+			// Check and radio marks go in the left gutter
+			if item.Checked {
+				mark := '√'
+				if item.RadioGroup != "" {
+					mark = '•'
+				}
+				s.SetContent(box.x+1, itemY, mark, nil, itemStyle)
 			}
 
-			// Draw the item text
-			PrintAt(s, menuX+2, itemY, item.Text, itemStyle)
+			// Draw the item text, underlining an explicit hotkey
+			if item.HotKey != 0 {
+				PrintMenuTitle(s, box.x+2, itemY, item.Text, unicode.ToLower(item.HotKey), itemStyle)
+			} else {
+				PrintAt(s, box.x+2, itemY, item.Text, itemStyle)
+			}
+
+			// Shortcuts are right-aligned, before any submenu indicator
+			right := box.x + box.width - 2
+			if len(item.Items) > 0 {
+				s.SetContent(right-1, itemY, '►', nil, itemStyle)
+				right -= 2
+			}
+			if item.Shortcut != "" {
+				PrintAt(s, right-len(item.Shortcut), itemY, item.Shortcut, itemStyle)
+			}
 		}
 	}
 }
 
+// DrawDropdownMenu draws a dropdown menu under a menu bar item
+func DrawDropdownMenu(s Surface, menu Menu, activeMenuItem int, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor tcell.Color) {
+	DrawCascadingMenu(s, menu, activeMenuItem, nil, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor)
+}
+
+// DrawCascadingMenu draws a dropdown menu together with its open submenus.
+// submenuPath holds the active item of each open submenu, outermost first.
+func DrawCascadingMenu(s Surface, menu Menu, activeMenuItem int, submenuPath []int, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor tcell.Color) {
+	for _, box := range cascadeBoxes(s, menu, activeMenuItem, submenuPath) {
+		drawMenuBox(s, box, dropdownFg, dropdownBg, dropdownActiveFg, dropdownActiveBg, separatorColor)
+	}
+}
+
+// CascadingMenuHit finds the open menu box under (mouseX, mouseY). level is 0
+// for the dropdown and n for the nth open submenu; index is the item row, or
+// -1 when the point is on the box border. ok is false if no box is hit.
+func CascadingMenuHit(s Surface, menu Menu, activeMenuItem int, submenuPath []int, mouseX, mouseY int) (level, index int, ok bool) {
+	return hitMenuBoxes(cascadeBoxes(s, menu, activeMenuItem, submenuPath), mouseX, mouseY)
+}
+
+// hitMenuBoxes finds the box and item under (mouseX, mouseY), see CascadingMenuHit
+func hitMenuBoxes(boxes []menuBox, mouseX, mouseY int) (level, index int, ok bool) {
+	// Submenus are drawn over their parents, so check the deepest first
+	for level = len(boxes) - 1; level >= 0; level-- {
+		box := boxes[level]
+		if mouseX >= box.x && mouseX < box.x+box.width && mouseY >= box.y && mouseY < box.y+box.height {
+			index = rowItem(box.items, mouseY-box.y-1)
+			return level, index, true
+		}
+	}
+	return 0, -1, false
+}
+
+// IsSelectable reports whether a dropdown item can be highlighted and chosen
+func (item DropdownItem) IsSelectable() bool {
+	return !item.IsSeparator && !item.Disabled && !item.Hidden
+}
+
+// visibleCount returns the number of items that are not hidden
+func visibleCount(items []DropdownItem) int {
+	n := 0
+	for _, item := range items {
+		if !item.Hidden {
+			n++
+		}
+	}
+	return n
+}
+
+// itemRow returns the row on which the item at index is drawn
+func itemRow(items []DropdownItem, index int) int {
+	row := 0
+	for i := 0; i < index && i < len(items); i++ {
+		if !items[i].Hidden {
+			row++
+		}
+	}
+	return row
+}
+
+// rowItem returns the index of the item drawn on row, or -1 if there is none
+func rowItem(items []DropdownItem, row int) int {
+	if row < 0 {
+		return -1
+	}
+	for i, item := range items {
+		if item.Hidden {
+			continue
+		}
+		if row == 0 {
+			return i
+		}
+		row--
+	}
+	return -1
+}
+
+// RefreshMenuItems evaluates the LabelFunc, EnabledFunc and VisibleFunc of
+// items and their submenus, storing the results in Text, Disabled and Hidden
+func RefreshMenuItems(items []DropdownItem) {
+	for i := range items {
+		items[i].refresh()
+		RefreshMenuItems(items[i].Items)
+	}
+}
+
+// refresh evaluates the callbacks of a single item
+func (item *DropdownItem) refresh() {
+	if item.LabelFunc != nil {
+		item.Text = item.LabelFunc()
+	}
+	if item.EnabledFunc != nil {
+		item.Disabled = !item.EnabledFunc()
+	}
+	if item.VisibleFunc != nil {
+		item.Hidden = !item.VisibleFunc()
+	}
+}
+
+// Refresh prepares the menu for display: it calls OnOpen and then evaluates
+// the item callbacks, see RefreshMenuItems
+func (m *Menu) Refresh() {
+	if m.OnOpen != nil {
+		m.OnOpen(m)
+	}
+	RefreshMenuItems(m.Items)
+}
+
+// NextMenuItem returns the index of the next selectable item after from, moving
+// by dir (1 for down, -1 for up) and skipping separators, disabled and
+// hidden items.
+// It returns from unchanged if there is no such item.
+func NextMenuItem(items []DropdownItem, from, dir int) int {
+	for i := from + dir; i >= 0 && i < len(items); i += dir {
+		if items[i].IsSelectable() {
+			return i
+		}
+	}
+	return from
+}
+
+// ActivateMenuItem chooses the item at index: checkable items toggle, radio
+// items become the checked item of their group, and OnSelect is called.
+// It returns false if the item cannot be selected.
+func ActivateMenuItem(s tcell.Screen, items []DropdownItem, index int) bool {
+	if index < 0 || index >= len(items) || !items[index].IsSelectable() {
+		return false
+	}
+
+	item := &items[index]
+	switch {
+	case item.RadioGroup != "":
+		for i := range items {
+			if items[i].RadioGroup == item.RadioGroup {
+				items[i].Checked = i == index
+			}
+		}
+	case item.Checkable:
+		item.Checked = !item.Checked
+	}
+
+	if item.OnSelect != nil {
+		item.OnSelect(s)
+	}
+	return true
+}
+
 // DrawSelectionDialog draws a central selection dialog with menu items
-func DrawSelectionDialog(s tcell.Screen, menuItems []MenuItem, selected int, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg tcell.Color) {
+func DrawSelectionDialog(s Surface, menuItems []MenuItem, selected int, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg tcell.Color) {
+	DrawSelectionDialogWithBorder(s, menuItems, selected, BorderStyle{}, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg)
+}
+
+// DrawSelectionDialogWithBorder is DrawSelectionDialog with the given border;
+// the zero BorderStyle keeps the single lines
+func DrawSelectionDialogWithBorder(s Surface, menuItems []MenuItem, selected int, border BorderStyle, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg tcell.Color) {
 	width, height := s.Size()
 
 	// Compute dimensions so the dialog fits the menu text
@@ -128,6 +404,7 @@ func DrawSelectionDialog(s tcell.Screen, menuItems []MenuItem, selected int, sel
 		FillRune:           ' ',
 		ShadowEnabled:      true,
 		DoubleLine:         false,
+		Border:             border,
 	}
 
 	DrawBox(s, dialogX, dialogY, dialogWidth, dialogHeight, tcell.ColorBlack, selectionBg, dialogOptions)
@@ -137,7 +414,7 @@ func DrawSelectionDialog(s tcell.Screen, menuItems []MenuItem, selected int, sel
 }
 
 // DrawMenuItems draws the menu items inside the selection dialog
-func DrawMenuItems(s tcell.Screen, dialogX, dialogY int, menuItems []MenuItem, selected int, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg tcell.Color) {
+func DrawMenuItems(s Surface, dialogX, dialogY int, menuItems []MenuItem, selected int, selectionBg, selectionActiveBg, selectionNumFg, selectionTextFg tcell.Color) {
 	// Render menu items inside the dialog with white numbers and black text
 	menuStartY := dialogY + 2
 	for i, item := range menuItems {
@@ -168,11 +445,17 @@ func DrawMenuItems(s tcell.Screen, dialogX, dialogY int, menuItems []MenuItem, s
 }
 
 // DrawInstructionBox draws the instruction box with dynamic text based on selection
-func DrawInstructionBox(s tcell.Screen, menuItems []MenuItem, selected int, defaultInstructionText string, instructionFg, instructionBg tcell.Color) {
+func DrawInstructionBox(s Surface, menuItems []MenuItem, selected int, defaultInstructionText string, instructionFg, instructionBg tcell.Color) {
+	DrawInstructionBoxWithBorder(s, menuItems, selected, defaultInstructionText, BorderStyle{}, instructionFg, instructionBg)
+}
+
+// DrawInstructionBoxWithBorder is DrawInstructionBox with the given border;
+// the zero BorderStyle keeps the single lines
+func DrawInstructionBoxWithBorder(s Surface, menuItems []MenuItem, selected int, defaultInstructionText string, border BorderStyle, instructionFg, instructionBg tcell.Color) {
 	width, height := s.Size()
 
 	instrBoxHeight := 4
-	instrBoxWidth := 70
+	instrBoxWidth := min(70, width-4) // Shrink on narrow terminals
 	instrBoxX := (width - instrBoxWidth) / 2
 	instrBoxY := height - 3 - instrBoxHeight
 
@@ -182,6 +465,7 @@ func DrawInstructionBox(s tcell.Screen, menuItems []MenuItem, selected int, defa
 		FillRune:           ' ',
 		ShadowEnabled:      true,
 		DoubleLine:         false,
+		Border:             border,
 	}
 
 	DrawBox(s, instrBoxX, instrBoxY, instrBoxWidth, instrBoxHeight, tcell.ColorWhite, instructionBg, instrOptions)
@@ -208,7 +492,13 @@ func DrawInstructionBox(s tcell.Screen, menuItems []MenuItem, selected int, defa
 }
 
 // DrawTitleBox draws the title box at the top of the screen
-func DrawTitleBox(s tcell.Screen, appName string, copyrightText string, titleFg, titleBg tcell.Color) {
+func DrawTitleBox(s Surface, appName string, copyrightText string, titleFg, titleBg tcell.Color) {
+	DrawTitleBoxWithBorder(s, appName, copyrightText, BorderStyle{}, titleFg, titleBg)
+}
+
+// DrawTitleBoxWithBorder is DrawTitleBox with the given border; the zero
+// BorderStyle keeps the double lines
+func DrawTitleBoxWithBorder(s Surface, appName string, copyrightText string, border BorderStyle, titleFg, titleBg tcell.Color) {
 	width, _ := s.Size()
 	titleBoxHeight := 4
 
@@ -218,6 +508,7 @@ func DrawTitleBox(s tcell.Screen, appName string, copyrightText string, titleFg,
 		FillRune:           ' ',
 		ShadowEnabled:      false,
 		DoubleLine:         true,
+		Border:             border,
 	}
 
 	// Draw a double-line box across the top
@@ -227,7 +518,7 @@ func DrawTitleBox(s tcell.Screen, appName string, copyrightText string, titleFg,
 }
 
 // DrawBottomBar draws the status bar at the bottom of the screen
-func DrawBottomBar(s tcell.Screen, statusText string, statusFg, statusBg tcell.Color) {
+func DrawBottomBar(s Surface, statusText string, statusFg, statusBg tcell.Color) {
 	width, height := s.Size()
 
 	bottomBoxHeight := 1
@@ -266,7 +557,7 @@ func DrawSimpleMessage(s tcell.Screen, message string, fgColor, bgColor tcell.Co
 }
 
 // DrawBackground fills the entire screen with the main background color/pattern
-func DrawBackground(s tcell.Screen, bgColor tcell.Color, patternEnabled bool, patternRune rune) {
+func DrawBackground(s Surface, bgColor tcell.Color, patternEnabled bool, patternRune rune) {
 	options := DrawOptions{
 		FillPatternEnabled: patternEnabled,
 		FillRune:           patternRune,
@@ -0,0 +1,596 @@
+package retrotui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// MenuBarColors holds the colors of a menu bar and its dropdown menus
+type MenuBarColors struct {
+	BarFg            tcell.Color
+	BarBg            tcell.Color
+	ActiveFg         tcell.Color
+	ActiveBg         tcell.Color
+	DropdownFg       tcell.Color
+	DropdownBg       tcell.Color
+	DropdownActiveFg tcell.Color
+	DropdownActiveBg tcell.Color
+	SeparatorColor   tcell.Color
+}
+
+// DefaultMenuBarColors returns the classic menu bar color scheme
+func DefaultMenuBarColors() MenuBarColors {
+	return MenuBarColors{
+		BarFg:            tcell.ColorWhite,
+		BarBg:            tcell.ColorDarkBlue,
+		ActiveFg:         tcell.ColorWhite,
+		ActiveBg:         tcell.ColorRed,
+		DropdownFg:       tcell.ColorBlack,
+		DropdownBg:       tcell.ColorLightGray,
+		DropdownActiveFg: tcell.ColorWhite,
+		DropdownActiveBg: tcell.ColorBlue,
+		SeparatorColor:   tcell.ColorGray,
+	}
+}
+
+// EventMenuSelect is posted to the screen's event queue after a menu item was chosen
+type EventMenuSelect struct {
+	t    time.Time
+	Menu int           // Index of the menu in the menu bar
+	Path []int         // Item index at each menu level, outermost first
+	Item *DropdownItem // The chosen item
+}
+
+// When returns the time the item was chosen
+func (ev *EventMenuSelect) When() time.Time {
+	return ev.t
+}
+
+// MenuBar owns a row of drop-down menus and their keyboard and mouse
+// navigation. Alt+hotkey opens a menu, F10 activates the bar, the arrow keys,
+// Enter and Esc navigate, and typing an item's hotkey chooses it.
+type MenuBar struct {
+	Menus     []Menu
+	Colors    MenuBarColors
+	Spacing   int          // Cells between menu titles when positions are computed
+	Shortcuts *ShortcutMap // Item shortcuts, active even when the menus are closed
+	Keymap    *Keymap      // Navigation keys while the bar is active; scope WidgetScope("menu")
+
+	active int     // Index of the highlighted menu, -1 when the bar is inactive
+	open   bool    // true when the dropdown of the active menu is shown
+	nav    menuNav // Highlighted items of the open dropdown and its submenus
+}
+
+// NewMenuBar creates a menu bar for menus and registers their item shortcuts.
+// It returns an error if a shortcut cannot be parsed or is used twice.
+func NewMenuBar(menus []Menu) (*MenuBar, error) {
+	shortcuts, err := RegisterMenuShortcuts(menus)
+	if err != nil {
+		return nil, err
+	}
+	return &MenuBar{
+		Menus:     menus,
+		Colors:    DefaultMenuBarColors(),
+		Spacing:   2,
+		Shortcuts: shortcuts,
+		active:    -1,
+	}, nil
+}
+
+// IsActive reports whether the menu bar currently has keyboard focus
+func (m *MenuBar) IsActive() bool {
+	return m.active >= 0
+}
+
+// IsOpen reports whether a dropdown menu is shown
+func (m *MenuBar) IsOpen() bool {
+	return m.active >= 0 && m.open
+}
+
+// Close closes any open menu and deactivates the bar
+func (m *MenuBar) Close() {
+	m.active = -1
+	m.open = false
+	m.nav.path = nil
+}
+
+// Layout computes Menu.Position for every menu from the titles: left-aligned
+// menus follow each other from the left edge, right-aligned menus hang from
+// the right edge
+func (m *MenuBar) Layout(width int) {
+	x := 1
+	for i := range m.Menus {
+		if !m.Menus[i].Align {
+			m.Menus[i].Position = x
+			x += len(m.Menus[i].Title) + m.Spacing
+		}
+	}
+	right := width
+	for i := len(m.Menus) - 1; i >= 0; i-- {
+		if m.Menus[i].Align {
+			right -= len(m.Menus[i].Title) + 2
+			m.Menus[i].Position = right
+		}
+	}
+}
+
+// Menu returns the menu with the given title, or nil if there is none.
+// Changes to the returned menu's items that add or remove items must be
+// followed by a call to Update.
+func (m *MenuBar) Menu(title string) *Menu {
+	for i := range m.Menus {
+		if m.Menus[i].Title == title {
+			return &m.Menus[i]
+		}
+	}
+	return nil
+}
+
+// FindItem returns the item with the given ID in any menu or submenu, or nil.
+// Changing its Text, Disabled, Hidden or Checked takes effect on the next draw.
+func (m *MenuBar) FindItem(id string) *DropdownItem {
+	for i := range m.Menus {
+		if item := findMenuItem(m.Menus[i].Items, id); item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+// findMenuItem searches items and their submenus for an item by ID
+func findMenuItem(items []DropdownItem, id string) *DropdownItem {
+	for i := range items {
+		if items[i].ID == id {
+			return &items[i]
+		}
+		if item := findMenuItem(items[i].Items, id); item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+// Update replaces the menus, closing any open menu and registering the item
+// shortcuts again. The menus are left unchanged if a shortcut is invalid or
+// used twice.
+func (m *MenuBar) Update(menus []Menu) error {
+	shortcuts, err := RegisterMenuShortcuts(menus)
+	if err != nil {
+		return err
+	}
+	m.Close()
+	m.Menus = menus
+	m.Shortcuts = shortcuts
+	return nil
+}
+
+// AddMenu appends a menu to the bar
+func (m *MenuBar) AddMenu(menu Menu) error {
+	return m.Update(append(slices.Clone(m.Menus), menu))
+}
+
+// RemoveMenu removes the menu with the given title. It returns false if
+// there is no such menu.
+func (m *MenuBar) RemoveMenu(title string) bool {
+	for i := range m.Menus {
+		if m.Menus[i].Title == title {
+			// Removing items cannot introduce a shortcut conflict
+			_ = m.Update(slices.Delete(slices.Clone(m.Menus), i, i+1))
+			return true
+		}
+	}
+	return false
+}
+
+// SetItems replaces the items of the menu with the given title
+func (m *MenuBar) SetItems(title string, items []DropdownItem) error {
+	for i := range m.Menus {
+		if m.Menus[i].Title == title {
+			menus := slices.Clone(m.Menus)
+			menus[i].Items = items
+			return m.Update(menus)
+		}
+	}
+	return fmt.Errorf("no menu titled %q", title)
+}
+
+// InsertItem inserts item at index in the menu with the given title. An
+// index outside the menu appends the item.
+func (m *MenuBar) InsertItem(title string, index int, item DropdownItem) error {
+	menu := m.Menu(title)
+	if menu == nil {
+		return fmt.Errorf("no menu titled %q", title)
+	}
+	if index < 0 || index > len(menu.Items) {
+		index = len(menu.Items)
+	}
+	return m.SetItems(title, slices.Insert(slices.Clone(menu.Items), index, item))
+}
+
+// RemoveItem removes the item with the given ID from whichever menu or
+// submenu holds it. It returns false if there is no such item.
+func (m *MenuBar) RemoveItem(id string) bool {
+	menus := slices.Clone(m.Menus)
+	for i := range menus {
+		if items, ok := removeMenuItem(menus[i].Items, id); ok {
+			menus[i].Items = items
+			_ = m.Update(menus)
+			return true
+		}
+	}
+	return false
+}
+
+// removeMenuItem returns a copy of items without the item with the given ID,
+// searching submenus too
+func removeMenuItem(items []DropdownItem, id string) ([]DropdownItem, bool) {
+	for i := range items {
+		if items[i].ID == id {
+			return slices.Delete(slices.Clone(items), i, i+1), true
+		}
+		if children, ok := removeMenuItem(items[i].Items, id); ok {
+			items = slices.Clone(items)
+			items[i].Items = children
+			return items, true
+		}
+	}
+	return items, false
+}
+
+// Draw renders the menu bar and, when open, the dropdown and its submenus.
+// Call it after the rest of the screen so the dropdown is on top.
+func (m *MenuBar) Draw(s tcell.Screen) {
+	width, _ := s.Size()
+	m.Layout(width)
+
+	c := m.Colors
+	DrawMenuBar(s, m.Menus, m.active, m.active >= 0, c.BarFg, c.BarBg, c.ActiveFg, c.ActiveBg)
+	if m.IsOpen() {
+		DrawCascadingMenu(s, m.Menus[m.active], m.nav.active, m.nav.path,
+			c.DropdownFg, c.DropdownBg, c.DropdownActiveFg, c.DropdownActiveBg, c.SeparatorColor)
+	}
+}
+
+// openMenu activates menu index and shows its dropdown
+func (m *MenuBar) openMenu(index int) {
+	menu := &m.Menus[index]
+	if menu.OnOpen != nil {
+		menu.OnOpen(menu)
+		// OnOpen may have replaced the items; keep the old shortcuts if the
+		// new ones clash
+		if shortcuts, err := RegisterMenuShortcuts(m.Menus); err == nil {
+			m.Shortcuts = shortcuts
+		}
+	}
+	RefreshMenuItems(menu.Items)
+
+	m.active = index
+	m.open = true
+	m.nav.reset(menu.Items)
+}
+
+// menuAction is the outcome of navigation inside an open menu
+type menuAction int
+
+const (
+	menuActionNone  menuAction = iota
+	menuActionPrev             // Left pressed in the outermost menu
+	menuActionNext             // Right pressed on an item without a submenu
+	menuActionClose            // Esc pressed in the outermost menu
+)
+
+// menuNav tracks the highlighted items of an open menu and its submenus and
+// implements the navigation shared by dropdown and context menus
+type menuNav struct {
+	active int   // Highlighted item of the outermost menu
+	path   []int // Highlighted item of each open submenu
+}
+
+// reset highlights the first selectable item and closes all submenus
+func (n *menuNav) reset(items []DropdownItem) {
+	n.active = firstMenuItem(items)
+	n.path = nil
+}
+
+// level returns the items of the innermost open menu box and a pointer to
+// its highlighted item
+func (n *menuNav) level(items []DropdownItem) ([]DropdownItem, *int) {
+	active := &n.active
+	for i := range n.path {
+		items = items[*active].Items
+		active = &n.path[i]
+	}
+	return items, active
+}
+
+// firstMenuItem returns the first selectable item, or -1 if there is none
+func firstMenuItem(items []DropdownItem) int {
+	for i, item := range items {
+		if item.IsSelectable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastMenuItem returns the last selectable item, or -1 if there is none
+func lastMenuItem(items []DropdownItem) int {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].IsSelectable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// itemHotKey returns the key that chooses an item in an open menu
+func itemHotKey(item DropdownItem) rune {
+	if item.HotKey != 0 {
+		return unicode.ToLower(item.HotKey)
+	}
+	for _, r := range item.Text {
+		return unicode.ToLower(r)
+	}
+	return 0
+}
+
+// choose opens the submenu of the item at index in the innermost menu, or
+// activates the item. onClose is called before a leaf item is activated so the
+// menu is closed while OnSelect runs. It returns the path of the activated
+// item, or nil if a submenu was opened or nothing could be chosen.
+func (n *menuNav) choose(s tcell.Screen, root []DropdownItem, index int, onClose func()) ([]int, *DropdownItem) {
+	items, active := n.level(root)
+	if index < 0 || index >= len(items) || !items[index].IsSelectable() {
+		return nil, nil
+	}
+	*active = index
+	if len(items[index].Items) > 0 {
+		n.path = append(n.path, firstMenuItem(items[index].Items))
+		return nil, nil
+	}
+
+	path := append([]int{n.active}, n.path...)
+	onClose()
+	if !ActivateMenuItem(s, items, index) {
+		return nil, nil
+	}
+	return path, &items[index]
+}
+
+// handleKey processes a key inside the open menu tree
+func (n *menuNav) handleKey(s tcell.Screen, root []DropdownItem, e *tcell.EventKey, onClose func()) (menuAction, []int, *DropdownItem) {
+	items, active := n.level(root)
+	switch e.Key() {
+	case tcell.KeyLeft:
+		if len(n.path) == 0 {
+			return menuActionPrev, nil, nil
+		}
+		// Close the innermost submenu
+		n.path = n.path[:len(n.path)-1]
+	case tcell.KeyRight:
+		if *active < 0 || len(items[*active].Items) == 0 {
+			return menuActionNext, nil, nil
+		}
+		n.choose(s, root, *active, onClose)
+	case tcell.KeyUp:
+		if next := NextMenuItem(items, *active, -1); next != *active {
+			*active = next
+		} else {
+			*active = lastMenuItem(items)
+		}
+	case tcell.KeyDown:
+		if next := NextMenuItem(items, *active, 1); next != *active {
+			*active = next
+		} else {
+			*active = firstMenuItem(items)
+		}
+	case tcell.KeyHome:
+		*active = firstMenuItem(items)
+	case tcell.KeyEnd:
+		*active = lastMenuItem(items)
+	case tcell.KeyEnter:
+		path, item := n.choose(s, root, *active, onClose)
+		return menuActionNone, path, item
+	case tcell.KeyEscape:
+		if len(n.path) == 0 {
+			return menuActionClose, nil, nil
+		}
+		n.path = n.path[:len(n.path)-1]
+	case tcell.KeyRune:
+		// Typing an item's hotkey chooses it
+		for i, item := range items {
+			if item.IsSelectable() && itemHotKey(item) == unicode.ToLower(e.Rune()) {
+				path, chosen := n.choose(s, root, i, onClose)
+				return menuActionNone, path, chosen
+			}
+		}
+	}
+	return menuActionNone, nil, nil
+}
+
+// handleMouse processes a mouse event over the item at index of menu level,
+// as found by hitMenuBoxes. Hovering highlights items and opens submenus,
+// clicking activates.
+func (n *menuNav) handleMouse(s tcell.Screen, root []DropdownItem, level, index int, buttons tcell.ButtonMask, onClose func()) ([]int, *DropdownItem) {
+	if index < 0 {
+		return nil, nil
+	}
+
+	// Hovering an item closes any deeper submenus
+	n.path = n.path[:level]
+	items, active := n.level(root)
+	if !items[index].IsSelectable() {
+		return nil, nil
+	}
+	*active = index
+	if len(items[index].Items) > 0 || buttons == tcell.ButtonPrimary {
+		// Hovering an item with children opens its submenu
+		return n.choose(s, root, index, onClose)
+	}
+	return nil, nil
+}
+
+// postSelect posts an EventMenuSelect for a chosen item
+func postSelect(s tcell.Screen, menu int, path []int, item *DropdownItem) {
+	if item != nil {
+		_ = s.PostEvent(&EventMenuSelect{t: time.Now(), Menu: menu, Path: path, Item: item})
+	}
+}
+
+// HandleEvent processes keyboard and mouse events for the menu bar.
+// It returns true if the event was consumed and the screen should be redrawn.
+func (m *MenuBar) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		return m.handleKey(s, e)
+	case *tcell.EventMouse:
+		return m.handleMouse(s, e)
+	case *tcell.EventResize:
+		// Positions are recomputed on the next draw
+		return false
+	}
+	return false
+}
+
+// handleKey processes a key event
+func (m *MenuBar) handleKey(s tcell.Screen, e *tcell.EventKey) bool {
+	// Alt+hotkey opens a menu from anywhere
+	if e.Modifiers()&tcell.ModAlt != 0 && e.Key() == tcell.KeyRune {
+		for i, menu := range m.Menus {
+			if unicode.ToLower(e.Rune()) == unicode.ToLower(menu.HotKey) {
+				m.openMenu(i)
+				return true
+			}
+		}
+	}
+
+	// While the bar is active its navigation keys take priority over item
+	// shortcuts, so e.g. the WordStar Ctrl+S moves left instead of saving
+	if m.IsActive() {
+		var consumed bool
+		if e, consumed = m.Keymap.translate(e, WidgetScope("menu")); consumed {
+			return true
+		}
+	}
+
+	if m.Shortcuts.HandleEvent(s, e) {
+		m.Close()
+		return true
+	}
+
+	if e.Key() == tcell.KeyF10 && e.Modifiers() == tcell.ModNone {
+		if m.IsActive() {
+			m.Close()
+		} else if len(m.Menus) > 0 {
+			m.active = 0
+			m.open = false
+		}
+		return true
+	}
+
+	if !m.IsActive() {
+		return false
+	}
+
+	if !m.open {
+		// The bar is focused but no dropdown is shown
+		switch e.Key() {
+		case tcell.KeyLeft:
+			m.active = (m.active - 1 + len(m.Menus)) % len(m.Menus)
+		case tcell.KeyRight:
+			m.active = (m.active + 1) % len(m.Menus)
+		case tcell.KeyDown, tcell.KeyEnter:
+			m.openMenu(m.active)
+		case tcell.KeyEscape:
+			m.Close()
+		case tcell.KeyRune:
+			for i, menu := range m.Menus {
+				if unicode.ToLower(e.Rune()) == unicode.ToLower(menu.HotKey) {
+					m.openMenu(i)
+					break
+				}
+			}
+		}
+		return true
+	}
+
+	menu := m.active
+	action, path, item := m.nav.handleKey(s, m.Menus[m.active].Items, e, m.Close)
+	switch action {
+	case menuActionPrev:
+		m.openMenu((m.active - 1 + len(m.Menus)) % len(m.Menus))
+	case menuActionNext:
+		m.openMenu((m.active + 1) % len(m.Menus))
+	case menuActionClose:
+		m.Close()
+	}
+	postSelect(s, menu, path, item)
+
+	// While the bar is active it keeps all keys to itself
+	return true
+}
+
+// titleAt returns the index of the menu whose title is at column x, or -1
+func (m *MenuBar) titleAt(s tcell.Screen, x int) int {
+	width, _ := s.Size()
+	m.Layout(width)
+	for i, menu := range m.Menus {
+		if x >= menu.Position && x < menu.Position+len(menu.Title) {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleMouse processes a mouse event
+func (m *MenuBar) handleMouse(s tcell.Screen, e *tcell.EventMouse) bool {
+	mouseX, mouseY := e.Position()
+	buttons := e.Buttons()
+
+	if mouseY == 0 {
+		index := m.titleAt(s, mouseX)
+		switch {
+		case index >= 0 && buttons == tcell.ButtonPrimary:
+			if m.IsOpen() && m.active == index {
+				m.Close()
+			} else {
+				m.openMenu(index)
+			}
+			return true
+		case index >= 0 && m.IsOpen() && index != m.active:
+			// Sliding along the bar with a menu open switches menus
+			m.openMenu(index)
+			return true
+		case buttons == tcell.ButtonPrimary && m.IsActive():
+			m.Close()
+			return true
+		}
+		return m.IsOpen()
+	}
+
+	if !m.IsOpen() {
+		if buttons == tcell.ButtonPrimary && m.IsActive() {
+			m.Close()
+			return true
+		}
+		return false
+	}
+
+	level, index, hit := CascadingMenuHit(s, m.Menus[m.active], m.nav.active, m.nav.path, mouseX, mouseY)
+	if !hit {
+		if buttons == tcell.ButtonPrimary {
+			m.Close()
+			return true
+		}
+		return false
+	}
+
+	menu := m.active
+	path, item := m.nav.handleMouse(s, m.Menus[m.active].Items, level, index, buttons, m.Close)
+	postSelect(s, menu, path, item)
+	return true
+}
@@ -0,0 +1,359 @@
+package retrotui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// MouseAction is the kind of a synthesized mouse event
+type MouseAction int
+
+const (
+	MousePress       MouseAction = iota // A button went down
+	MouseRelease                        // All buttons went up
+	MouseMove                           // The pointer moved with no button held
+	MouseClick                          // Press and release without a drag
+	MouseDoubleClick                    // Second press of a double click
+	MouseDragStart                      // The pointer moved past the drag threshold with a button held
+	MouseDrag                           // The pointer moved during a drag
+	MouseDrop                           // The buttons were released after a drag
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseHoverEnter // The pointer entered a target
+	MouseHoverLeave // The pointer left a target
+)
+
+// EventMouseAction is a mouse event synthesized by a MouseTracker from raw
+// tcell mouse events
+type EventMouseAction struct {
+	t              time.Time
+	Action         MouseAction
+	X, Y           int
+	Button         tcell.ButtonMask // Buttons involved in a press, release, click or drag
+	Mod            tcell.ModMask
+	StartX, StartY int               // Where the press that started a click or drag happened
+	Raw            *tcell.EventMouse // The tcell event this action was synthesized from
+}
+
+// When returns the time of the raw event
+func (ev *EventMouseAction) When() time.Time {
+	return ev.t
+}
+
+// MouseTarget is a window, menu or widget that receives synthesized mouse
+// events from a MouseTracker
+type MouseTarget interface {
+	// HitTest reports whether the target occupies screen cell (x, y)
+	HitTest(s tcell.Screen, x, y int) bool
+	// HandleMouse processes an action and reports whether it was consumed
+	HandleMouse(s tcell.Screen, ev *EventMouseAction) bool
+}
+
+// MouseTracker turns raw tcell mouse events into clicks, double clicks,
+// drags, wheel steps and hover changes and dispatches them to targets.
+// A target that consumes a press captures the following drag, release and
+// click events even when the pointer leaves it.
+type MouseTracker struct {
+	Targets         []MouseTarget // Top-most first
+	DoubleClickTime time.Duration // Maximum time between the clicks of a double click
+	DragThreshold   int           // Cells the pointer must move with a button held to start a drag
+	Drag            *DragManager  // Receives the pointer while a drag and drop is in progress
+
+	buttons   tcell.ButtonMask // Buttons currently held
+	pressX    int
+	pressY    int
+	dragging  bool
+	capture   MouseTarget // Target that consumed the last press
+	hover     MouseTarget // Target under the pointer
+	lastClick time.Time   // Time of the last click, zero after a double click
+	doubled   bool        // The current press completed a double click
+	clickX    int
+	clickY    int
+	clickBtn  tcell.ButtonMask
+}
+
+// NewMouseTracker creates a tracker for targets, top-most first
+func NewMouseTracker(targets ...MouseTarget) *MouseTracker {
+	return &MouseTracker{
+		Targets:         targets,
+		DoubleClickTime: 400 * time.Millisecond,
+		DragThreshold:   1,
+	}
+}
+
+// Translate returns the actions synthesized from a raw mouse event, in the
+// order they happened. Hover changes are not included as they depend on the
+// targets; see HandleEvent.
+func (t *MouseTracker) Translate(ev *tcell.EventMouse) []*EventMouseAction {
+	x, y := ev.Position()
+	mod := ev.Modifiers()
+	action := func(a MouseAction, button tcell.ButtonMask) *EventMouseAction {
+		return &EventMouseAction{t: ev.When(), Action: a, X: x, Y: y, Button: button, Mod: mod,
+			StartX: t.pressX, StartY: t.pressY, Raw: ev}
+	}
+
+	var actions []*EventMouseAction
+	raw := ev.Buttons()
+	for _, wheel := range []struct {
+		mask   tcell.ButtonMask
+		action MouseAction
+	}{
+		{tcell.WheelUp, MouseWheelUp},
+		{tcell.WheelDown, MouseWheelDown},
+		{tcell.WheelLeft, MouseWheelLeft},
+		{tcell.WheelRight, MouseWheelRight},
+	} {
+		if raw&wheel.mask != 0 {
+			actions = append(actions, action(wheel.action, tcell.ButtonNone))
+		}
+	}
+	if len(actions) > 0 {
+		return actions
+	}
+
+	buttons := raw & (tcell.Button1 | tcell.Button2 | tcell.Button3 | tcell.Button4 |
+		tcell.Button5 | tcell.Button6 | tcell.Button7 | tcell.Button8)
+	switch {
+	case t.buttons == tcell.ButtonNone && buttons != tcell.ButtonNone:
+		t.buttons = buttons
+		t.pressX, t.pressY = x, y
+		t.dragging = false
+		actions = append(actions, action(MousePress, buttons))
+		if !t.lastClick.IsZero() && buttons == t.clickBtn && x == t.clickX && y == t.clickY &&
+			ev.When().Sub(t.lastClick) <= t.DoubleClickTime {
+			actions = append(actions, action(MouseDoubleClick, buttons))
+			t.doubled = true
+		}
+
+	case t.buttons != tcell.ButtonNone && buttons == tcell.ButtonNone:
+		released := t.buttons
+		t.buttons = tcell.ButtonNone
+		actions = append(actions, action(MouseRelease, released))
+		if t.dragging {
+			t.dragging = false
+			actions = append(actions, action(MouseDrop, released))
+		} else {
+			actions = append(actions, action(MouseClick, released))
+			// The click ending a double click does not start another one
+			if t.doubled {
+				t.lastClick = time.Time{}
+			} else {
+				t.lastClick, t.clickX, t.clickY, t.clickBtn = ev.When(), x, y, released
+			}
+		}
+		t.doubled = false
+
+	case t.buttons != tcell.ButtonNone:
+		// Moving with a button held
+		if !t.dragging {
+			if max(abs(x-t.pressX), abs(y-t.pressY)) < t.DragThreshold {
+				return actions
+			}
+			t.dragging = true
+			actions = append(actions, action(MouseDragStart, t.buttons))
+		}
+		actions = append(actions, action(MouseDrag, t.buttons))
+
+	default:
+		actions = append(actions, action(MouseMove, tcell.ButtonNone))
+	}
+	return actions
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// HandleEvent synthesizes actions from a raw mouse event and dispatches them,
+// together with hover changes, to the targets. It returns true if any action
+// was consumed, in which case the raw event should not be handled again.
+func (t *MouseTracker) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	raw, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return false
+	}
+
+	consumed := false
+	x, y := raw.Position()
+	if hover := t.targetAt(s, x, y); hover != t.hover {
+		if t.hover != nil {
+			consumed = t.hover.HandleMouse(s, &EventMouseAction{t: raw.When(), Action: MouseHoverLeave, X: x, Y: y, Raw: raw}) || consumed
+		}
+		if hover != nil {
+			consumed = hover.HandleMouse(s, &EventMouseAction{t: raw.When(), Action: MouseHoverEnter, X: x, Y: y, Raw: raw}) || consumed
+		}
+		t.hover = hover
+	}
+
+	for _, a := range t.Translate(raw) {
+		consumed = t.dispatch(s, a) || consumed
+	}
+	return consumed
+}
+
+// targetAt returns the top-most target at (x, y), or nil
+func (t *MouseTracker) targetAt(s tcell.Screen, x, y int) MouseTarget {
+	for _, target := range t.Targets {
+		if target.HitTest(s, x, y) {
+			return target
+		}
+	}
+	return nil
+}
+
+// dispatch delivers an action to the capturing target, or to the top-most
+// target under the pointer that consumes it
+func (t *MouseTracker) dispatch(s tcell.Screen, a *EventMouseAction) bool {
+	if t.Drag.Active() {
+		switch a.Action {
+		case MouseDrag, MouseRelease, MouseDrop, MouseClick:
+			if a.Action == MouseDrop || a.Action == MouseClick {
+				t.capture = nil
+			}
+			return t.Drag.HandleMouse(s, a)
+		}
+	}
+
+	switch a.Action {
+	case MouseDragStart, MouseDrag, MouseRelease, MouseDrop, MouseClick:
+		if t.capture != nil {
+			target := t.capture
+			if a.Action == MouseClick || a.Action == MouseDrop {
+				t.capture = nil
+			}
+			return target.HandleMouse(s, a)
+		}
+	case MousePress:
+		t.capture = nil
+	}
+
+	for _, target := range t.Targets {
+		if target.HitTest(s, a.X, a.Y) && target.HandleMouse(s, a) {
+			if a.Action == MousePress {
+				t.capture = target
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// HitTest reports whether (x, y) is on the menu bar. While the bar is active
+// it claims the whole screen, so that a click anywhere closes its menus.
+func (m *MenuBar) HitTest(s tcell.Screen, x, y int) bool {
+	return y == 0 || m.IsActive()
+}
+
+// HandleMouse implements MouseTarget. Raw presses, releases and motion are
+// handled as by HandleEvent; the wheel moves the highlight of an open menu.
+func (m *MenuBar) HandleMouse(s tcell.Screen, ev *EventMouseAction) bool {
+	switch ev.Action {
+	case MousePress, MouseRelease, MouseMove, MouseDrag:
+		return m.handleMouse(s, ev.Raw)
+	case MouseWheelUp, MouseWheelDown:
+		if !m.IsOpen() {
+			return false
+		}
+		items, active := m.nav.level(m.Menus[m.active].Items)
+		dir := 1
+		if ev.Action == MouseWheelUp {
+			dir = -1
+		}
+		*active = NextMenuItem(items, *active, dir)
+		return true
+	}
+	return false
+}
+
+// HitTest reports whether (x, y) is on the field or, while open, anywhere on
+// screen, so that a click outside closes the list
+func (c *ComboBox) HitTest(s tcell.Screen, x, y int) bool {
+	return c.Open || (y == c.Y && x >= c.X && x < c.X+c.Width)
+}
+
+// HandleMouse implements MouseTarget. Raw presses and motion are handled as
+// by HandleEvent. The wheel scrolls an open list, or steps through the items
+// of a focused non-editable box.
+func (c *ComboBox) HandleMouse(s tcell.Screen, ev *EventMouseAction) bool {
+	switch ev.Action {
+	case MousePress, MouseMove, MouseDrag:
+		return c.HandleEvent(ev.Raw)
+	case MouseWheelUp, MouseWheelDown:
+		delta := 1
+		if ev.Action == MouseWheelUp {
+			delta = -1
+		}
+		switch {
+		case c.Open:
+			c.moveHighlight(delta)
+			return true
+		case c.Focused && !c.Editable && c.Selected+delta >= 0 && c.Selected+delta < len(c.Items):
+			c.SetSelected(c.Selected + delta)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// HitTest reports whether (x, y) lies within the visible window
+func (w *Window) HitTest(s tcell.Screen, x, y int) bool {
+	if !w.Visible {
+		return false
+	}
+	wx, wy, width, height := w.GetDimensions(s)
+	return x >= wx && x < wx+width && y >= wy && y < wy+height
+}
+
+// HandleMouse implements MouseTarget: double-clicking the title bar maximizes
+// or restores the window and the wheel scrolls a scrolling window. Other
+// actions are left to ManageWindows.
+func (w *Window) HandleMouse(s tcell.Screen, ev *EventMouseAction) bool {
+	if w.scrolls() {
+		switch ev.Action {
+		case MouseWheelUp:
+			w.ScrollBy(0, -1)
+			return true
+		case MouseWheelDown:
+			w.ScrollBy(0, 1)
+			return true
+		case MouseWheelLeft:
+			w.ScrollBy(-1, 0)
+			return true
+		case MouseWheelRight:
+			w.ScrollBy(1, 0)
+			return true
+		}
+	}
+	if ev.Action != MouseDoubleClick || ev.Button != tcell.ButtonPrimary {
+		return false
+	}
+	x, y, width, _ := w.GetDimensions(s)
+	// The control buttons take the right end of the title bar
+	if ev.Y != y || ev.X >= x+width-3*7 || w.State == windowStateMinimized {
+		return false
+	}
+	if w.State == windowStateMaximized {
+		w.State = windowStateNormal
+	} else {
+		w.State = windowStateMaximized
+	}
+	return true
+}
+
+// WindowTargets returns windows as mouse targets, top-most first, for use in
+// MouseTracker.Targets
+func WindowTargets(windows []*Window) []MouseTarget {
+	targets := make([]MouseTarget, 0, len(windows))
+	for i := len(windows) - 1; i >= 0; i-- {
+		targets = append(targets, windows[i])
+	}
+	return targets
+}
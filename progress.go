@@ -0,0 +1,351 @@
+package retrotui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ProgressStyle selects how a progress bar is rendered
+type ProgressStyle int
+
+const (
+	ProgressBlocks ProgressStyle = iota // █ cells with ▓▒░ for the partial cell
+	ProgressShaded                      // █ for done, ░ for remaining
+	ProgressHashes                      // [#####     ]
+)
+
+// Spinner frame sets
+var (
+	SpinnerLine   = []rune{'|', '/', '-', '\\'}
+	SpinnerBlocks = []rune{'▖', '▘', '▝', '▗'}
+	SpinnerCircle = []rune{'◐', '◓', '◑', '◒'}
+	SpinnerShade  = []rune{'░', '▒', '▓', '█', '▓', '▒'}
+)
+
+// EventRedraw is posted to the screen's event queue when a component was
+// updated from another goroutine. The event loop should redraw on receipt.
+type EventRedraw struct {
+	t      time.Time
+	Source any // The component that requested the redraw
+}
+
+// NewEventRedraw creates a redraw event for the given source
+func NewEventRedraw(source any) *EventRedraw {
+	return &EventRedraw{t: time.Now(), Source: source}
+}
+
+// When returns the time the event was created
+func (ev *EventRedraw) When() time.Time {
+	return ev.t
+}
+
+// DrawProgressBar draws a progress bar of the given width at (x,y).
+// fraction is clamped to the range 0..1. If showPercent is set the percentage
+// is printed in the middle of the bar (ProgressHashes prints it after the bar).
+func DrawProgressBar(s Surface, x, y, width int, fraction float64, style ProgressStyle, showPercent bool, fg, bg tcell.Color) {
+	if width < 1 {
+		return
+	}
+	fraction = max(0, min(fraction, 1))
+	barStyle := tcell.StyleDefault.Foreground(fg).Background(bg)
+	percent := fmt.Sprintf("%3d%%", int(fraction*100+0.5))
+
+	if style == ProgressHashes {
+		inner := width - 2
+		if showPercent {
+			inner -= len(percent) + 1
+		}
+		if inner < 1 {
+			return
+		}
+		done := int(fraction*float64(inner) + 0.5)
+		bar := "[" + strings.Repeat("#", done) + strings.Repeat(" ", inner-done) + "]"
+		if showPercent {
+			bar += " " + percent
+		}
+		PrintAt(s, x, y, bar, barStyle)
+		return
+	}
+
+	// Work in eighths of a cell so the partial cell can be shaded
+	total := int(fraction*float64(width)*8 + 0.5)
+	full := total / 8
+	partial := total % 8
+	for i := 0; i < width; i++ {
+		r := ' '
+		if style == ProgressShaded {
+			r = '░'
+		}
+		switch {
+		case i < full:
+			r = '█'
+		case i == full && style == ProgressBlocks:
+			switch {
+			case partial >= 6:
+				r = '▓'
+			case partial >= 4:
+				r = '▒'
+			case partial >= 2:
+				r = '░'
+			}
+		}
+		s.SetContent(x+i, y, r, nil, barStyle)
+	}
+
+	if showPercent && width >= len(percent) {
+		// Text over the filled part is drawn reversed so it stays readable
+		px := x + (width-len(percent))/2
+		for i, r := range percent {
+			cellStyle := barStyle
+			if px+i-x < full {
+				cellStyle = tcell.StyleDefault.Foreground(bg).Background(fg)
+			}
+			s.SetContent(px+i, y, r, nil, cellStyle)
+		}
+	}
+}
+
+// DrawMarquee draws an indeterminate progress bar: a block that bounces
+// between the ends of the bar. frame selects the animation step.
+func DrawMarquee(s Surface, x, y, width, frame int, style ProgressStyle, fg, bg tcell.Color) {
+	if width < 1 {
+		return
+	}
+	barStyle := tcell.StyleDefault.Foreground(fg).Background(bg)
+
+	inner, left := width, x
+	if style == ProgressHashes {
+		if width < 3 {
+			return
+		}
+		inner, left = width-2, x+1
+		s.SetContent(x, y, '[', nil, barStyle)
+		s.SetContent(x+width-1, y, ']', nil, barStyle)
+	}
+
+	block := max(1, inner/4)
+	travel := inner - block
+	pos := 0
+	if travel > 0 {
+		pos = frame % (2 * travel)
+		if pos > travel {
+			pos = 2*travel - pos
+		}
+	}
+
+	filled, empty := '█', ' '
+	switch style {
+	case ProgressShaded:
+		empty = '░'
+	case ProgressHashes:
+		filled = '#'
+	}
+	for i := 0; i < inner; i++ {
+		r := empty
+		if i >= pos && i < pos+block {
+			r = filled
+		}
+		s.SetContent(left+i, y, r, nil, barStyle)
+	}
+}
+
+// animator runs a step function periodically in a background goroutine
+type animator struct {
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// start begins calling step every interval, replacing any running animation
+func (a *animator) start(interval time.Duration, step func()) {
+	a.halt()
+	a.mu.Lock()
+	stop := make(chan struct{})
+	a.stop = stop
+	a.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				step()
+			}
+		}
+	}()
+}
+
+// halt stops the running animation, if any
+func (a *animator) halt() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+// ProgressBar is a progress bar that may be updated from any goroutine.
+// Updates post an EventRedraw to the screen so the event loop can redraw it.
+type ProgressBar struct {
+	X           int
+	Y           int
+	Width       int
+	Style       ProgressStyle
+	ShowPercent bool
+
+	mu            sync.Mutex
+	value         float64
+	indeterminate bool
+	frame         int
+	screen        tcell.Screen
+	anim          animator
+}
+
+// NewProgressBar creates a new progress bar that posts redraws to s
+func NewProgressBar(s tcell.Screen, x, y, width int) *ProgressBar {
+	return &ProgressBar{
+		X:           x,
+		Y:           y,
+		Width:       width,
+		Style:       ProgressBlocks,
+		ShowPercent: true,
+		screen:      s,
+	}
+}
+
+// SetValue sets the completed fraction (0..1) and requests a redraw
+func (p *ProgressBar) SetValue(fraction float64) {
+	p.mu.Lock()
+	p.value = max(0, min(fraction, 1))
+	p.indeterminate = false
+	p.mu.Unlock()
+	p.anim.halt()
+	p.requestRedraw()
+}
+
+// Value returns the completed fraction
+func (p *ProgressBar) Value() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value
+}
+
+// StartMarquee switches the bar to indeterminate mode, animating it every interval
+func (p *ProgressBar) StartMarquee(interval time.Duration) {
+	p.mu.Lock()
+	p.indeterminate = true
+	p.mu.Unlock()
+	p.anim.start(interval, p.Tick)
+}
+
+// StopMarquee stops the indeterminate animation and returns to showing the value
+func (p *ProgressBar) StopMarquee() {
+	p.anim.halt()
+	p.mu.Lock()
+	p.indeterminate = false
+	p.mu.Unlock()
+	p.requestRedraw()
+}
+
+// Tick advances the marquee animation by one frame and requests a redraw
+func (p *ProgressBar) Tick() {
+	p.mu.Lock()
+	p.frame++
+	p.mu.Unlock()
+	p.requestRedraw()
+}
+
+// Draw renders the progress bar. It must be called from the event loop goroutine.
+func (p *ProgressBar) Draw(s tcell.Screen, fg, bg tcell.Color) {
+	p.mu.Lock()
+	value, indeterminate, frame := p.value, p.indeterminate, p.frame
+	p.mu.Unlock()
+
+	if indeterminate {
+		DrawMarquee(s, p.X, p.Y, p.Width, frame, p.Style, fg, bg)
+		return
+	}
+	DrawProgressBar(s, p.X, p.Y, p.Width, value, p.Style, p.ShowPercent, fg, bg)
+}
+
+// requestRedraw posts a redraw event without blocking if the queue is full
+func (p *ProgressBar) requestRedraw() {
+	if p.screen != nil {
+		_ = p.screen.PostEvent(NewEventRedraw(p))
+	}
+}
+
+// Spinner is an animated activity indicator with an optional label
+type Spinner struct {
+	X      int
+	Y      int
+	Frames []rune
+	Label  string
+
+	mu     sync.Mutex
+	frame  int
+	screen tcell.Screen
+	anim   animator
+}
+
+// NewSpinner creates a new spinner that posts redraws to s
+func NewSpinner(s tcell.Screen, x, y int, frames []rune) *Spinner {
+	if len(frames) == 0 {
+		frames = SpinnerLine
+	}
+	return &Spinner{
+		X:      x,
+		Y:      y,
+		Frames: frames,
+		screen: s,
+	}
+}
+
+// Start animates the spinner every interval from a background goroutine
+func (sp *Spinner) Start(interval time.Duration) {
+	sp.anim.start(interval, sp.Tick)
+}
+
+// Stop stops the spinner animation
+func (sp *Spinner) Stop() {
+	sp.anim.halt()
+}
+
+// Tick advances the spinner by one frame and requests a redraw
+func (sp *Spinner) Tick() {
+	sp.mu.Lock()
+	sp.frame = (sp.frame + 1) % len(sp.frames())
+	sp.mu.Unlock()
+	if sp.screen != nil {
+		_ = sp.screen.PostEvent(NewEventRedraw(sp))
+	}
+}
+
+// frames returns the animation frames, falling back to SpinnerLine when Frames is empty
+func (sp *Spinner) frames() []rune {
+	if len(sp.Frames) == 0 {
+		return SpinnerLine
+	}
+	return sp.Frames
+}
+
+// Draw renders the spinner and its label. It must be called from the event loop goroutine.
+func (sp *Spinner) Draw(s tcell.Screen, fg, bg tcell.Color) {
+	sp.mu.Lock()
+	frame := sp.frame
+	sp.mu.Unlock()
+
+	style := tcell.StyleDefault.Foreground(fg).Background(bg)
+	frames := sp.frames()
+	s.SetContent(sp.X, sp.Y, frames[frame%len(frames)], nil, style)
+	if sp.Label != "" {
+		PrintAt(s, sp.X+2, sp.Y, sp.Label, style)
+	}
+}
@@ -0,0 +1,61 @@
+package retrotui
+
+import (
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ResizeHandler is called with the new screen size after a terminal resize
+type ResizeHandler func(s tcell.Screen, width, height int)
+
+// resizeHandlers holds the handlers registered with OnResize
+var resizeHandlers = struct {
+	mu       sync.Mutex
+	next     int
+	handlers map[int]ResizeHandler
+}{handlers: make(map[int]ResizeHandler)}
+
+// OnResize registers a handler that HandleResize calls after every resize.
+// It returns a function that removes the handler again.
+func OnResize(handler ResizeHandler) func() {
+	resizeHandlers.mu.Lock()
+	defer resizeHandlers.mu.Unlock()
+
+	id := resizeHandlers.next
+	resizeHandlers.next++
+	resizeHandlers.handlers[id] = handler
+
+	return func() {
+		resizeHandlers.mu.Lock()
+		defer resizeHandlers.mu.Unlock()
+		delete(resizeHandlers.handlers, id)
+	}
+}
+
+// HandleResize processes a *tcell.EventResize: it resynchronises the screen
+// and calls the handlers registered with OnResize. It returns true if ev was
+// a resize event, in which case the caller should redraw everything.
+func HandleResize(s tcell.Screen, ev tcell.Event) bool {
+	if _, ok := ev.(*tcell.EventResize); !ok {
+		return false
+	}
+
+	s.Sync()
+	width, height := s.Size()
+
+	resizeHandlers.mu.Lock()
+	handlers := make([]ResizeHandler, 0, len(resizeHandlers.handlers))
+	for id := 0; id < resizeHandlers.next; id++ {
+		if h, ok := resizeHandlers.handlers[id]; ok {
+			handlers = append(handlers, h)
+		}
+	}
+	resizeHandlers.mu.Unlock()
+
+	// Handlers run unlocked so they may register or remove handlers themselves
+	for _, h := range handlers {
+		h(s, width, height)
+	}
+	return true
+}
@@ -4,18 +4,73 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-// DrawShadow draws a drop shadow using the full block character (█) in black.
-// The shadow is drawn at an offset of (1,1) relative to the given rectangle.
-func DrawShadow(s tcell.Screen, x, y, w, h int, options DrawOptions) {
+// ShadowMode selects how DrawShadow paints a shadow
+type ShadowMode int
+
+const (
+	ShadowSolid       ShadowMode = iota // Paint ShadowRune in ShadowColor on black
+	ShadowTransparent                   // Keep the characters underneath and dim their colors
+)
+
+// DrawShadow draws a drop shadow for the given rectangle. The shadow is the
+// rectangle moved by the shadow offset, (1,1) unless set in options, less the
+// rectangle itself; negative offsets cast it up or to the left. A solid shadow
+// uses ShadowRune (█) in ShadowColor, a transparent one dims what is below.
+// Shadows are clipped at every edge of the surface.
+func DrawShadow(s Surface, x, y, w, h int, options DrawOptions) {
 	shadowStyle := tcell.StyleDefault.Foreground(options.ShadowColor).Background(tcell.ColorBlack)
-	sw, sh := s.Size() // Get screen dimensions
+	dx, dy := options.ShadowOffsetX, options.ShadowOffsetY
+	if dx == 0 && dy == 0 {
+		dx, dy = 1, 1
+	}
+	// Only the part on the surface is drawn, wherever the box is
+	box := Rect{X: x, Y: y, Width: w, Height: h}
+	r := clipRect(s, Rect{X: x + dx, Y: y + dy, Width: w, Height: h})
+	for j := r.Y; j < r.Y+r.Height; j++ {
+		for i := r.X; i < r.X+r.Width; i++ {
+			if box.Contains(i, j) {
+				continue
+			}
+			if options.ShadowMode == ShadowTransparent {
+				mainc, combc, style, _ := s.GetContent(i, j)
+				s.SetContent(i, j, mainc, combc, DimStyle(style))
+				continue
+			}
+			s.SetContent(i, j, options.ShadowRune, nil, shadowStyle)
+		}
+	}
+}
 
-	// Draw right shadow: column x+w at rows y+1 to y+h.
-	for j := y + 1; j < y+h+1 && j < sh; j++ {
-		s.SetContent(x+w, j, options.ShadowRune, nil, shadowStyle)
+// DimStyle returns style with its colors darkened, as seen in a shadow.
+// Characters whose color would vanish into the background are drawn in dark
+// gray so they stay readable.
+func DimStyle(style tcell.Style) tcell.Style {
+	fg, bg, _ := style.Decompose()
+	fg, bg = DimColor(fg), DimColor(bg)
+	if fg == bg {
+		fg = tcell.ColorGray
+	}
+	return style.Foreground(fg).Background(bg).Bold(false)
+}
+
+// DimColor returns a darker version of c: the bright colors of the 16-color
+// palette become their dark counterparts and the dark ones black, as on the
+// classic shadowed text screens. Other colors are halved in brightness.
+func DimColor(c tcell.Color) tcell.Color {
+	switch c {
+	case tcell.ColorDefault, tcell.ColorReset:
+		return c
+	}
+	if c >= tcell.ColorBlack && c <= tcell.ColorWhite {
+		// The bright half of the palette maps to the dark half
+		if c >= tcell.ColorGray {
+			return c - 8
+		}
+		return tcell.ColorBlack
 	}
-	// Draw bottom shadow: row y+h at columns x+1 to x+w.
-	for i := x + 1; i < x+w+1 && i < sw; i++ {
-		s.SetContent(i, y+h, options.ShadowRune, nil, shadowStyle)
+	r, g, b := c.RGB()
+	if r < 0 {
+		return c
 	}
+	return tcell.NewRGBColor(r/2, g/2, b/2)
 }
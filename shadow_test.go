@@ -0,0 +1,118 @@
+package retrotui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// shadowOffsets are the offsets the shadow tests cast shadows at
+var shadowOffsets = []struct{ dx, dy int }{{0, 0}, {2, 1}, {-1, -1}, {-2, 1}, {1, -2}}
+
+func TestDrawShadowClipped(t *testing.T) {
+	for _, mode := range []ShadowMode{ShadowSolid, ShadowTransparent} {
+		for _, o := range shadowOffsets {
+			t.Run(fmt.Sprintf("mode%d/%d,%d", mode, o.dx, o.dy), func(t *testing.T) {
+				options := DrawOptions{ShadowMode: mode, ShadowRune: '█', ShadowColor: tcell.ColorBlack,
+					ShadowOffsetX: o.dx, ShadowOffsetY: o.dy}
+				checkClipped(t, func(s Surface, x, y int) {
+					DrawShadow(s, x, y, 8, 5, options)
+				})
+			})
+		}
+	}
+}
+
+func TestDrawBoxShadowClipped(t *testing.T) {
+	for _, mode := range []ShadowMode{ShadowSolid, ShadowTransparent} {
+		for _, o := range shadowOffsets {
+			t.Run(fmt.Sprintf("mode%d/%d,%d", mode, o.dx, o.dy), func(t *testing.T) {
+				options := DrawOptions{ShadowEnabled: true, ShadowMode: mode, ShadowOffsetX: o.dx, ShadowOffsetY: o.dy}
+				checkClipped(t, func(s Surface, x, y int) {
+					DrawBox(s, x, y, 8, 5, tcell.ColorWhite, tcell.ColorNavy, options)
+				})
+			})
+		}
+	}
+}
+
+func TestDrawShadowCells(t *testing.T) {
+	under := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	s := &recordingSurface{Canvas: NewCanvas(screenW, screenH)}
+	s.Fill('x', under)
+
+	// The shadow of a box in the bottom-right corner hangs off both edges;
+	// only its column at x=19 is on screen
+	DrawShadow(s, 13, 6, 6, 4, DrawOptions{ShadowMode: ShadowTransparent, ShadowOffsetX: 2, ShadowOffsetY: 1})
+	if s.outside > 0 {
+		t.Fatalf("%d writes outside the screen", s.outside)
+	}
+	for y := 0; y < screenH; y++ {
+		for x := 0; x < screenW; x++ {
+			shadowed := x == 19 && y >= 7
+			r, _, style, _ := s.GetContent(x, y)
+			want := under
+			if shadowed {
+				want = DimStyle(under)
+			}
+			if r != 'x' || style != want {
+				t.Fatalf("cell %d,%d is %q %v, want 'x' %v", x, y, r, style, want)
+			}
+		}
+	}
+}
+
+func TestDrawShadowOffScreen(t *testing.T) {
+	s := &recordingSurface{Canvas: NewCanvas(screenW, screenH)}
+	for _, o := range shadowOffsets {
+		options := DrawOptions{ShadowRune: '█', ShadowOffsetX: o.dx, ShadowOffsetY: o.dy}
+		DrawShadow(s, -40, 3, 8, 5, options)
+		DrawShadow(s, 3, 40, 8, 5, options)
+		DrawShadow(s, 100, -100, 8, 5, options)
+	}
+	if s.outside > 0 {
+		t.Fatalf("%d writes outside the screen", s.outside)
+	}
+	if b := s.Bounds(); b != (Rect{}) {
+		t.Fatalf("shadows of off-screen boxes drew %+v", b)
+	}
+}
+
+func TestDrawShadowSimulationScreen(t *testing.T) {
+	s := newSimulationScreen(t, screenW, screenH)
+	under := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorGreen)
+
+	shadowed := 0
+	for _, p := range positions {
+		for _, o := range shadowOffsets {
+			for _, mode := range []ShadowMode{ShadowSolid, ShadowTransparent} {
+				options := DrawOptions{ShadowMode: mode, ShadowRune: '█', ShadowOffsetX: o.dx, ShadowOffsetY: o.dy}
+				s.Fill('.', under)
+				DrawShadow(s, p.x, p.y, 8, 5, options)
+				s.Show()
+
+				// The terminal shows what an unclipped shadow leaves on screen
+				ref := NewCanvas(screenW+2*margin, screenH+2*margin)
+				ref.Fill('.', under)
+				DrawShadow(ref, p.x+margin, p.y+margin, 8, 5, options)
+				for y := 0; y < screenH; y++ {
+					for x := 0; x < screenW; x++ {
+						r, _, style, _ := s.GetContent(x, y)
+						want, _ := ref.at(x+margin, y+margin)
+						if r != want.mainc || style != want.style {
+							t.Fatalf("box at %d,%d, mode %d, offset %d,%d: cell %d,%d is %q %v, want %q %v",
+								p.x, p.y, mode, o.dx, o.dy, x, y, r, style, want.mainc, want.style)
+						}
+						if style != under {
+							shadowed++
+						}
+					}
+				}
+			}
+		}
+	}
+	if shadowed == 0 {
+		t.Fatal("no shadow reached the screen")
+	}
+}
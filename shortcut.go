@@ -0,0 +1,247 @@
+package retrotui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// KeyCombo is a single key press together with its modifiers
+type KeyCombo struct {
+	Key  tcell.Key // tcell.KeyRune for printable characters
+	Rune rune      // The character when Key is tcell.KeyRune
+	Mod  tcell.ModMask
+}
+
+// namedKeys maps the key names accepted by ParseKeyCombo to tcell keys
+var namedKeys = map[string]tcell.Key{
+	"enter":     tcell.KeyEnter,
+	"return":    tcell.KeyEnter,
+	"esc":       tcell.KeyEscape,
+	"escape":    tcell.KeyEscape,
+	"tab":       tcell.KeyTab,
+	"backtab":   tcell.KeyBacktab,
+	"backspace": tcell.KeyBackspace2,
+	"delete":    tcell.KeyDelete,
+	"del":       tcell.KeyDelete,
+	"insert":    tcell.KeyInsert,
+	"ins":       tcell.KeyInsert,
+	"home":      tcell.KeyHome,
+	"end":       tcell.KeyEnd,
+	"pgup":      tcell.KeyPgUp,
+	"pageup":    tcell.KeyPgUp,
+	"pgdn":      tcell.KeyPgDn,
+	"pagedown":  tcell.KeyPgDn,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+}
+
+// ParseKeyCombo parses a key description such as "Ctrl+S", "Alt+X", "F5",
+// "Shift+F10", "Ctrl+PgUp" or "q". Modifier and key names are case-insensitive.
+func ParseKeyCombo(text string) (KeyCombo, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return KeyCombo{}, fmt.Errorf("empty key combination")
+	}
+
+	parts := strings.Split(text, "+")
+	switch {
+	case text == "+":
+		parts = []string{"+"}
+	case strings.HasSuffix(text, "++"):
+		// A trailing "+" is the plus key itself, e.g. "Ctrl++"
+		parts = append(strings.Split(strings.TrimSuffix(text, "++"), "+"), "+")
+	}
+
+	var combo KeyCombo
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(mod)) {
+		case "ctrl", "control":
+			combo.Mod |= tcell.ModCtrl
+		case "alt", "meta":
+			combo.Mod |= tcell.ModAlt
+		case "shift":
+			combo.Mod |= tcell.ModShift
+		default:
+			return KeyCombo{}, fmt.Errorf("unknown modifier %q in %q", mod, text)
+		}
+	}
+
+	name := strings.TrimSpace(parts[len(parts)-1])
+	lower := strings.ToLower(name)
+	runes := []rune(name)
+
+	switch {
+	case lower == "menu":
+		combo.Key = tcell.KeyF10
+		combo.Mod |= tcell.ModShift
+	case lower == "space":
+		combo.Key, combo.Rune = tcell.KeyRune, ' '
+	case len(lower) > 1 && lower[0] == 'f' && isDigits(lower[1:]):
+		n, _ := strconv.Atoi(lower[1:])
+		if n < 1 || n > 64 {
+			return KeyCombo{}, fmt.Errorf("invalid function key %q", name)
+		}
+		combo.Key = tcell.KeyF1 + tcell.Key(n-1)
+	case namedKeys[lower] != 0:
+		combo.Key = namedKeys[lower]
+	case len(runes) == 1:
+		r := runes[0]
+		if combo.Mod&tcell.ModCtrl != 0 && unicode.IsLetter(r) && r < unicode.MaxASCII {
+			// Ctrl+letter arrives as its own control key
+			combo.Key = tcell.KeyCtrlA + tcell.Key(unicode.ToLower(r)-'a')
+			combo.Mod &^= tcell.ModCtrl
+		} else {
+			combo.Key, combo.Rune = tcell.KeyRune, r
+		}
+	default:
+		return KeyCombo{}, fmt.Errorf("unknown key %q in %q", name, text)
+	}
+	return combo, nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether the key event is this key combination
+func (k KeyCombo) Matches(ev *tcell.EventKey) bool {
+	if ev.Key() != k.Key {
+		return false
+	}
+
+	// Control keys already encode the Ctrl modifier
+	mods := ev.Modifiers()
+	if k.Key >= tcell.KeyCtrlA && k.Key <= tcell.KeyCtrlZ {
+		mods &^= tcell.ModCtrl
+	}
+
+	if k.Key == tcell.KeyRune {
+		if ev.Rune() != k.Rune {
+			// Letters are matched case-insensitively when Alt is held, since
+			// terminals differ in how they report Alt+Shift
+			if k.Mod&tcell.ModAlt == 0 || unicode.ToLower(ev.Rune()) != unicode.ToLower(k.Rune) {
+				return false
+			}
+		}
+		// Shift is implied by the character itself
+		return mods&^tcell.ModShift == k.Mod&^tcell.ModShift
+	}
+	return mods == k.Mod
+}
+
+// String returns the key combination in the form accepted by ParseKeyCombo
+func (k KeyCombo) String() string {
+	var b strings.Builder
+	mod := k.Mod
+	if k.Key >= tcell.KeyCtrlA && k.Key <= tcell.KeyCtrlZ {
+		mod |= tcell.ModCtrl
+	}
+	if mod&tcell.ModCtrl != 0 {
+		b.WriteString("Ctrl+")
+	}
+	if mod&tcell.ModAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if mod&tcell.ModShift != 0 {
+		b.WriteString("Shift+")
+	}
+
+	switch {
+	case k.Key == tcell.KeyRune && k.Rune == ' ':
+		b.WriteString("Space")
+	case k.Key == tcell.KeyRune:
+		b.WriteRune(k.Rune)
+	case k.Key >= tcell.KeyCtrlA && k.Key <= tcell.KeyCtrlZ:
+		b.WriteRune(rune('A' + k.Key - tcell.KeyCtrlA))
+	case k.Key >= tcell.KeyF1 && k.Key <= tcell.KeyF64:
+		fmt.Fprintf(&b, "F%d", k.Key-tcell.KeyF1+1)
+	default:
+		if name, ok := tcell.KeyNames[k.Key]; ok {
+			b.WriteString(name)
+		} else {
+			fmt.Fprintf(&b, "Key(%d)", k.Key)
+		}
+	}
+	return b.String()
+}
+
+// shortcutEntry links a key combination to a menu item
+type shortcutEntry struct {
+	combo KeyCombo
+	items []DropdownItem // The slice holding the item, so check state is shared with the menu
+	index int
+}
+
+// ShortcutMap dispatches key combinations to the menu items that show them,
+// so their OnSelect fires even while the menus are closed
+type ShortcutMap struct {
+	entries []shortcutEntry
+}
+
+// RegisterMenuShortcuts collects the Shortcut of every item in menus and
+// their submenus. It returns an error for a shortcut that cannot be parsed or
+// is used by more than one item.
+func RegisterMenuShortcuts(menus []Menu) (*ShortcutMap, error) {
+	m := &ShortcutMap{}
+	for i := range menus {
+		if err := m.register(menus[i].Items); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// register adds the shortcuts of items and their children
+func (m *ShortcutMap) register(items []DropdownItem) error {
+	for i, item := range items {
+		if item.Shortcut != "" {
+			combo, err := ParseKeyCombo(item.Shortcut)
+			if err != nil {
+				return fmt.Errorf("menu item %q: %w", item.Text, err)
+			}
+			for _, e := range m.entries {
+				if e.combo == combo {
+					return fmt.Errorf("shortcut %s is used by both %q and %q", item.Shortcut, e.items[e.index].Text, item.Text)
+				}
+			}
+			m.entries = append(m.entries, shortcutEntry{combo: combo, items: items, index: i})
+		}
+		if err := m.register(item.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleEvent activates the menu item whose shortcut matches a key event.
+// It returns true if a shortcut matched, even if the item was disabled.
+func (m *ShortcutMap) HandleEvent(s tcell.Screen, ev tcell.Event) bool {
+	e, ok := ev.(*tcell.EventKey)
+	if !ok || m == nil {
+		return false
+	}
+	for _, entry := range m.entries {
+		if entry.combo.Matches(e) {
+			// The menu may be closed, so its callbacks have not been evaluated
+			entry.items[entry.index].refresh()
+			ActivateMenuItem(s, entry.items, entry.index)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// SplitOrientation selects how a SplitPane arranges its children
+type SplitOrientation int
+
+const (
+	SplitVertical   SplitOrientation = iota // Side by side, separated by │
+	SplitHorizontal                         // Stacked, separated by ─
+)
+
+// SplitPane is a container that divides its area between two child widgets.
+// The divider can be dragged with the mouse, or moved with Ctrl+arrow keys
+// along the split axis. F6 moves keyboard focus between the panes.
+type SplitPane struct {
+	Orientation SplitOrientation
+	First       Widget
+	Second      Widget
+	Ratio       float64 // Share of the space given to First when FixedSize is 0
+	FixedSize   int     // If > 0, First gets exactly this many cells
+	MinSize     int     // Minimum size of either pane, in cells
+	Bordered    bool    // Draw an outer box that the divider joins with junctions
+	JoinParent  bool    // Join the divider to a border drawn around the pane by the parent
+	DoubleLine  bool
+	Focus       int            // 0 when First has keyboard focus, 1 for Second
+	OnResize    func(size int) // Called with the new size of First when the divider moves
+
+	// Colors
+	BorderFg tcell.Color
+	BorderBg tcell.Color
+
+	dragging bool
+
+	// Geometry from the last draw, used for mouse hit testing
+	x, y, width, height int
+	divider             int // Absolute column or row of the divider
+}
+
+// NewSplitPane creates a split pane with the space shared equally
+func NewSplitPane(orientation SplitOrientation, first, second Widget) *SplitPane {
+	return &SplitPane{
+		Orientation: orientation,
+		First:       first,
+		Second:      second,
+		Ratio:       0.5,
+		MinSize:     1,
+		BorderFg:    tcell.ColorWhite,
+		BorderBg:    tcell.ColorBlue,
+	}
+}
+
+// inner returns the area inside the optional outer border
+func (p *SplitPane) inner() (x, y, width, height int) {
+	if p.Bordered {
+		return p.x + 1, p.y + 1, p.width - 2, p.height - 2
+	}
+	return p.x, p.y, p.width, p.height
+}
+
+// span returns the length of the split axis inside the border
+func (p *SplitPane) span() int {
+	_, _, w, h := p.inner()
+	if p.Orientation == SplitVertical {
+		return w
+	}
+	return h
+}
+
+// firstSize returns the size of the first pane for the given total space,
+// excluding the one cell taken by the divider
+func (p *SplitPane) firstSize(total int) int {
+	avail := total - 1
+	size := p.FixedSize
+	if size <= 0 {
+		size = int(p.Ratio*float64(avail) + 0.5)
+	}
+	return p.clampFirst(size, avail)
+}
+
+// clampFirst limits the size of the first pane to the avail cells beside the
+// divider, keeping both panes at least MinSize where there is room for it
+func (p *SplitPane) clampFirst(size, avail int) int {
+	minSize := max(p.MinSize, 0)
+	return max(min(size, avail-minSize), min(minSize, avail))
+}
+
+// Draw renders the panes and the divider
+func (p *SplitPane) Draw(s tcell.Screen, x, y, width, height int) {
+	p.x, p.y, p.width, p.height = x, y, width, height
+
+	borderSt := tcell.StyleDefault.Foreground(p.BorderFg).Background(p.BorderBg)
+	if p.Bordered {
+		if width < 3 || height < 3 {
+			return
+		}
+		boxOptions := DrawOptions{
+			FillPatternEnabled: false,
+			FillRune:           ' ',
+			ShadowEnabled:      false,
+			DoubleLine:         p.DoubleLine,
+		}
+		DrawBox(s, x, y, width, height, p.BorderFg, p.BorderBg, boxOptions)
+	}
+
+	ix, iy, iw, ih := p.inner()
+	if iw < 1 || ih < 1 || p.span() < 2 {
+		return
+	}
+	size := p.firstSize(p.span())
+
+	// Divider glyphs: the line itself, then its junctions at the start and end
+	line, startJoin, endJoin := '│', '┬', '┴'
+	if p.Orientation == SplitHorizontal {
+		line, startJoin, endJoin = '─', '├', '┤'
+	}
+	if p.DoubleLine {
+		line, startJoin, endJoin = '║', '╦', '╩'
+		if p.Orientation == SplitHorizontal {
+			line, startJoin, endJoin = '═', '╠', '╣'
+		}
+	}
+
+	if p.Orientation == SplitVertical {
+		p.divider = ix + size
+		for j := iy; j < iy+ih; j++ {
+			s.SetContent(p.divider, j, line, nil, borderSt)
+		}
+		if p.Bordered {
+			s.SetContent(p.divider, y, startJoin, nil, borderSt)
+			s.SetContent(p.divider, y+height-1, endJoin, nil, borderSt)
+		} else if p.JoinParent {
+			p.joinBorder(s, p.divider, y-1, true)
+			p.joinBorder(s, p.divider, y+height, false)
+		}
+		if p.First != nil {
+			p.First.Draw(s, ix, iy, size, ih)
+		}
+		if p.Second != nil {
+			p.Second.Draw(s, p.divider+1, iy, iw-size-1, ih)
+		}
+		return
+	}
+
+	p.divider = iy + size
+	for i := ix; i < ix+iw; i++ {
+		s.SetContent(i, p.divider, line, nil, borderSt)
+	}
+	if p.Bordered {
+		s.SetContent(x, p.divider, startJoin, nil, borderSt)
+		s.SetContent(x+width-1, p.divider, endJoin, nil, borderSt)
+	} else if p.JoinParent {
+		p.joinBorder(s, x-1, p.divider, true)
+		p.joinBorder(s, x+width, p.divider, false)
+	}
+	if p.First != nil {
+		p.First.Draw(s, ix, iy, iw, size)
+	}
+	if p.Second != nil {
+		p.Second.Draw(s, ix, p.divider+1, iw, ih-size-1)
+	}
+}
+
+// joinBorder turns the parent's border cell at (x, y) into a junction with the
+// divider. start is true for the top or left end of the divider. Borders of
+// any line weight are joined; ASCII borders get a '+'.
+func (p *SplitPane) joinBorder(s tcell.Screen, x, y int, start bool) {
+	r, _, style, _ := s.GetContent(x, y)
+	weight := LineSingle
+	if p.DoubleLine {
+		weight = LineDouble
+	}
+	var end LineArms
+	switch {
+	case p.Orientation == SplitVertical && start:
+		end.Down = weight
+	case p.Orientation == SplitVertical:
+		end.Up = weight
+	case start:
+		end.Right = weight
+	default:
+		end.Left = weight
+	}
+
+	joined := r
+	if arms, ok := ArmsOf(r); ok {
+		if merged, ok := RuneFor(arms.merge(end)); ok {
+			joined = merged
+		}
+	} else if r == '-' && p.Orientation == SplitVertical || r == '|' && p.Orientation == SplitHorizontal {
+		joined = '+'
+	}
+	if joined != r {
+		s.SetContent(x, y, joined, nil, style)
+	}
+}
+
+// moveDivider moves the divider by delta cells, keeping both panes at least MinSize
+func (p *SplitPane) moveDivider(delta int) {
+	total := p.span()
+	if total < 2 {
+		return
+	}
+	size := p.clampFirst(p.firstSize(total)+delta, total-1)
+	if p.FixedSize > 0 {
+		p.FixedSize = size
+	} else {
+		p.Ratio = float64(size) / float64(total-1)
+	}
+
+	// Keep the hit-testing position current until the next draw
+	ix, iy, _, _ := p.inner()
+	if p.Orientation == SplitVertical {
+		p.divider = ix + size
+	} else {
+		p.divider = iy + size
+	}
+	if p.OnResize != nil {
+		p.OnResize(size)
+	}
+}
+
+// HandleEvent moves the divider and focus, and forwards other events to the panes.
+// It returns true if the event was handled.
+func (p *SplitPane) HandleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		if e.Key() == tcell.KeyF6 {
+			p.Focus = 1 - p.Focus
+			return true
+		}
+		if e.Modifiers()&tcell.ModCtrl != 0 {
+			back, forward := tcell.KeyLeft, tcell.KeyRight
+			if p.Orientation == SplitHorizontal {
+				back, forward = tcell.KeyUp, tcell.KeyDown
+			}
+			switch e.Key() {
+			case back:
+				p.moveDivider(-1)
+				return true
+			case forward:
+				p.moveDivider(1)
+				return true
+			}
+		}
+		if pane := p.focused(); pane != nil {
+			return pane.HandleEvent(ev)
+		}
+		return false
+
+	case *tcell.EventMouse:
+		mouseX, mouseY := e.Position()
+		buttons := e.Buttons()
+
+		pos := mouseX
+		if p.Orientation == SplitHorizontal {
+			pos = mouseY
+		}
+
+		if p.dragging {
+			if buttons&tcell.ButtonPrimary == 0 {
+				p.dragging = false
+				return true
+			}
+			p.moveDivider(pos - p.divider)
+			return true
+		}
+
+		ix, iy, iw, ih := p.inner()
+		if mouseX < ix || mouseX >= ix+iw || mouseY < iy || mouseY >= iy+ih {
+			return false
+		}
+
+		if pos == p.divider {
+			if buttons == tcell.ButtonPrimary {
+				p.dragging = true
+			}
+			return true
+		}
+
+		// Route to the pane under the mouse; clicking a pane focuses it
+		target := 0
+		if pos > p.divider {
+			target = 1
+		}
+		if buttons == tcell.ButtonPrimary {
+			p.Focus = target
+		}
+		pane := p.First
+		if target == 1 {
+			pane = p.Second
+		}
+		if pane != nil {
+			pane.HandleEvent(ev)
+		}
+		return true
+	}
+
+	if pane := p.focused(); pane != nil {
+		return pane.HandleEvent(ev)
+	}
+	return false
+}
+
+// focused returns the pane with keyboard focus
+func (p *SplitPane) focused() Widget {
+	if p.Focus == 1 {
+		return p.Second
+	}
+	return p.First
+}
@@ -0,0 +1,28 @@
+package retrotui
+
+import "testing"
+
+func TestMoveDividerSmallPane(t *testing.T) {
+	for _, width := range []int{2, 3, 8, 11, 40} {
+		for _, fixed := range []int{0, 4} {
+			p := NewSplitPane(SplitVertical, nil, nil)
+			p.MinSize = 5
+			p.FixedSize = fixed
+			p.Draw(NewCanvas(width, 5).Screen(nil), 0, 0, width, 5)
+			avail := width - 1
+
+			for _, delta := range []int{3, 100, -3, -100, 1} {
+				p.moveDivider(delta)
+				if p.Ratio < 0 || p.Ratio > 1 {
+					t.Fatalf("width %d, fixed %d: ratio %v after moving by %d", width, fixed, p.Ratio, delta)
+				}
+				if p.FixedSize > avail {
+					t.Fatalf("width %d, fixed %d: fixed size %d after moving by %d, only %d cells", width, fixed, p.FixedSize, delta, avail)
+				}
+				if size := p.firstSize(width); avail >= 2*p.MinSize && (size < p.MinSize || avail-size < p.MinSize) {
+					t.Fatalf("width %d, fixed %d: panes of %d and %d after moving by %d", width, fixed, size, avail-size, delta)
+				}
+			}
+		}
+	}
+}
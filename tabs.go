@@ -0,0 +1,210 @@
+package retrotui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Tab is a single page of a TabContainer
+type Tab struct {
+	Title   string
+	Content Widget
+}
+
+// tabSpan records where a tab title was drawn on the top border
+type tabSpan struct {
+	start int
+	end   int
+	index int
+}
+
+// TabContainer hosts several child widgets, showing one at a time, with the
+// tab titles drawn on its top border. Tabs are switched by clicking a title,
+// Ctrl+PgUp/Ctrl+PgDn, or Alt+1..9.
+type TabContainer struct {
+	Tabs       []Tab
+	Active     int
+	DoubleLine bool
+	OnChange   func(index int) // Called when the active tab changes
+
+	// Colors
+	BorderFg tcell.Color
+	BorderBg tcell.Color
+	TabFg    tcell.Color
+	TabBg    tcell.Color
+	ActiveFg tcell.Color
+	ActiveBg tcell.Color
+
+	first int // First tab shown when the titles overflow the width
+
+	// Geometry from the last draw, used for mouse hit testing
+	x, y, width, height int
+	spans               []tabSpan
+	leftArrow           bool
+	rightArrow          bool
+}
+
+// NewTabContainer creates a new, empty tab container with default colors
+func NewTabContainer() *TabContainer {
+	return &TabContainer{
+		DoubleLine: true,
+		BorderFg:   tcell.ColorWhite,
+		BorderBg:   tcell.ColorBlue,
+		TabFg:      tcell.ColorYellow,
+		TabBg:      tcell.ColorBlue,
+		ActiveFg:   tcell.ColorBlack,
+		ActiveBg:   tcell.ColorTeal,
+	}
+}
+
+// AddTab appends a tab and returns its index
+func (t *TabContainer) AddTab(title string, content Widget) int {
+	t.Tabs = append(t.Tabs, Tab{Title: title, Content: content})
+	return len(t.Tabs) - 1
+}
+
+// SetActive makes the tab at index the active one
+func (t *TabContainer) SetActive(index int) {
+	if index < 0 || index >= len(t.Tabs) || index == t.Active {
+		return
+	}
+	t.Active = index
+	if t.OnChange != nil {
+		t.OnChange(index)
+	}
+}
+
+// Draw renders the container border, the tab titles and the active tab's content
+func (t *TabContainer) Draw(s tcell.Screen, x, y, width, height int) {
+	t.x, t.y, t.width, t.height = x, y, width, height
+	if width < 6 || height < 3 {
+		return
+	}
+
+	boxOptions := DrawOptions{
+		FillPatternEnabled: false,
+		FillRune:           ' ',
+		ShadowEnabled:      false,
+		DoubleLine:         t.DoubleLine,
+	}
+	DrawBox(s, x, y, width, height, t.BorderFg, t.BorderBg, boxOptions)
+
+	t.layoutTabs()
+	borderSt := tcell.StyleDefault.Foreground(t.BorderFg).Background(t.BorderBg)
+	for _, span := range t.spans {
+		tabSt := tcell.StyleDefault.Foreground(t.TabFg).Background(t.TabBg)
+		if span.index == t.Active {
+			tabSt = tcell.StyleDefault.Foreground(t.ActiveFg).Background(t.ActiveBg)
+		}
+		PrintAt(s, x+span.start, y, "[ "+t.Tabs[span.index].Title+" ]", tabSt)
+	}
+
+	// Overflow arrows
+	if t.leftArrow {
+		s.SetContent(x+1, y, '◄', nil, borderSt)
+	}
+	if t.rightArrow {
+		s.SetContent(x+width-2, y, '►', nil, borderSt)
+	}
+
+	if t.Active >= 0 && t.Active < len(t.Tabs) && t.Tabs[t.Active].Content != nil {
+		t.Tabs[t.Active].Content.Draw(s, x+1, y+1, width-2, height-2)
+	}
+}
+
+// layoutTabs decides which titles fit on the top border, keeping the active tab visible
+func (t *TabContainer) layoutTabs() {
+	if t.Active < t.first {
+		t.first = t.Active
+	}
+	for {
+		t.computeSpans()
+		if t.first >= t.Active || t.containsTab(t.Active) {
+			break
+		}
+		t.first++
+	}
+}
+
+// computeSpans lays out tab titles starting from t.first
+func (t *TabContainer) computeSpans() {
+	t.spans = t.spans[:0]
+	t.leftArrow = t.first > 0
+	t.rightArrow = false
+
+	// Leave room for the corners and the overflow arrows
+	pos := 2
+	limit := t.width - 2
+	for i := t.first; i < len(t.Tabs); i++ {
+		w := len(t.Tabs[i].Title) + 4
+		if pos+w > limit {
+			t.rightArrow = true
+			break
+		}
+		t.spans = append(t.spans, tabSpan{start: pos, end: pos + w, index: i})
+		pos += w
+	}
+}
+
+// containsTab reports whether the tab at index was laid out
+func (t *TabContainer) containsTab(index int) bool {
+	for _, span := range t.spans {
+		if span.index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleEvent switches tabs on keyboard and mouse input and forwards other
+// events to the active tab's content. It returns true if the event was handled.
+func (t *TabContainer) HandleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventKey:
+		if e.Modifiers()&tcell.ModCtrl != 0 {
+			switch e.Key() {
+			case tcell.KeyPgUp:
+				if len(t.Tabs) > 0 {
+					t.SetActive((t.Active - 1 + len(t.Tabs)) % len(t.Tabs))
+				}
+				return true
+			case tcell.KeyPgDn:
+				if len(t.Tabs) > 0 {
+					t.SetActive((t.Active + 1) % len(t.Tabs))
+				}
+				return true
+			}
+		}
+		if e.Key() == tcell.KeyRune && e.Modifiers()&tcell.ModAlt != 0 {
+			if r := e.Rune(); r >= '1' && r <= '9' && int(r-'1') < len(t.Tabs) {
+				t.SetActive(int(r - '1'))
+				return true
+			}
+		}
+
+	case *tcell.EventMouse:
+		mouseX, mouseY := e.Position()
+		if mouseY == t.y && e.Buttons() == tcell.ButtonPrimary && mouseX >= t.x && mouseX < t.x+t.width {
+			rel := mouseX - t.x
+			// The overflow arrows step to the tab just out of view
+			if t.leftArrow && rel == 1 {
+				t.SetActive(t.first - 1)
+				return true
+			}
+			if t.rightArrow && rel == t.width-2 && len(t.spans) > 0 {
+				t.SetActive(t.spans[len(t.spans)-1].index + 1)
+				return true
+			}
+			for _, span := range t.spans {
+				if rel >= span.start && rel < span.end {
+					t.SetActive(span.index)
+					return true
+				}
+			}
+		}
+	}
+
+	if t.Active >= 0 && t.Active < len(t.Tabs) && t.Tabs[t.Active].Content != nil {
+		return t.Tabs[t.Active].Content.HandleEvent(ev)
+	}
+	return false
+}
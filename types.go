@@ -11,7 +11,12 @@ type DrawOptions struct {
 	ShadowEnabled      bool        // true for shadow, false for no shadow
 	ShadowRune         rune        // shadow character █
 	ShadowColor        tcell.Color // color of the shadow
+	ShadowMode         ShadowMode  // solid or transparent shadow
+	ShadowOffsetX      int         // columns the shadow is cast right, negative for left; (0,0) means (1,1)
+	ShadowOffsetY      int         // rows the shadow is cast down, negative for up
 	DoubleLine         bool        // true for double line box, false for single line
+	Border             BorderStyle // border characters; overrides DoubleLine when set
+	MergeBorders       bool        // join the border to box-drawing lines already drawn where they meet
 }
 
 // MenuItem struct to hold menu item text and its instruction
@@ -25,8 +30,9 @@ type Menu struct {
 	Title    string
 	HotKey   rune // The key after Alt to activate this menu
 	Items    []DropdownItem
-	Position int  // x position on menu bar
-	Align    bool // false = left, true = right
+	Position int           // x position on menu bar
+	Align    bool          // false = left, true = right
+	OnOpen   func(m *Menu) // Called before the menu opens, e.g. to rebuild a recent-files list
 }
 
 // DropdownItem represents an item within a dropdown menu
@@ -34,6 +40,18 @@ type DropdownItem struct {
 	Text        string
 	IsSeparator bool
 	OnSelect    func(s tcell.Screen)
+	Items       []DropdownItem // Child items; when set the item opens a submenu
+	Shortcut    string         // Key combination shown right-aligned, e.g. "Ctrl+S"
+	Disabled    bool           // Disabled items are greyed out and cannot be selected
+	Checkable   bool           // Selecting the item toggles Checked
+	Checked     bool           // Shows a check mark, or a radio mark in a RadioGroup
+	RadioGroup  string         // Items of one menu sharing a group act as radio buttons
+	HotKey      rune           // Key that chooses the item in an open menu; defaults to the first letter
+	Hidden      bool           // Hidden items are not shown and cannot be selected
+	ID          string         // Identifies the item for MenuBar.FindItem and friends
+	EnabledFunc func() bool    // When set, computes Disabled each time the menu opens
+	VisibleFunc func() bool    // When set, computes Hidden each time the menu opens
+	LabelFunc   func() string  // When set, computes Text each time the menu opens
 }
 
 // UIState holds the current state of the UI
@@ -41,6 +59,7 @@ type UIState struct {
 	Background       tcell.Color
 	ActiveMenu       int // -1 for no active menu
 	ActiveMenuItem   int
+	SubmenuPath      []int  // Active item in each open submenu, outermost first
 	CurrentScreen    string // Identifies which screen is currently active
 	MenuBarActive    bool
 	MenuItems        []MenuItem // For the menu screen
@@ -71,10 +90,18 @@ type MenuConfig struct {
 	SelectionTextFg   tcell.Color
 	InstructionBoxFg  tcell.Color
 	InstructionBoxBg  tcell.Color
+	ColorDepth        ColorDepth // Colors the menu is drawn with; zero detects the terminal's
+	CGAPalette        bool       // Forces every color through the IBM text-mode palette
+	BlinkMode         BlinkMode  // What the blink attribute does with CGAPalette
+
+	// Borders; unset borders keep the classic double title box and single dialogs
+	TitleBorder  BorderStyle
+	DialogBorder BorderStyle
 
 	// Key bindings
 	ExitKeys  []tcell.Key
 	ExitRunes []rune
+	Keymap    *Keymap // When set, replaces ExitKeys, ExitRunes and the arrow keys; scope WidgetScope("list")
 
 	// Behavior options
 	ReturnToMenuAfterSelection bool
@@ -91,6 +118,8 @@ const (
 	NavRight
 	NavSelect
 	NavExit
+	NavTop
+	NavBottom
 )
 
 // KeyConfig holds configuration for keyboard shortcuts
@@ -102,4 +131,26 @@ type KeyConfig struct {
 	NavLeftKey  tcell.Key   // Key for navigating left
 	NavRightKey tcell.Key   // Key for navigating right
 	SelectKey   tcell.Key   // Key for selection
+	Keymap      *Keymap     // When set, replaces all of the keys above
+}
+
+// Widget is a component that draws itself into a rectangle and handles events.
+// Containers such as TabContainer lay out and forward events to child widgets.
+type Widget interface {
+	Draw(s tcell.Screen, x, y, width, height int)
+	HandleEvent(ev tcell.Event) bool
+}
+
+// DrawFunc adapts a content drawing function, like Window.Content, to a Widget
+// that ignores events
+type DrawFunc func(s tcell.Screen, x, y, width, height int)
+
+// Draw calls the function
+func (f DrawFunc) Draw(s tcell.Screen, x, y, width, height int) {
+	f(s, x, y, width, height)
+}
+
+// HandleEvent ignores all events
+func (f DrawFunc) HandleEvent(ev tcell.Event) bool {
+	return false
 }
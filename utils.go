@@ -2,26 +2,36 @@ package retrotui
 
 import (
 	"strings"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-// PrintAt writes text at (x,y) using a given style.
-func PrintAt(s tcell.Screen, x, y int, text string, style tcell.Style) {
-	for i, r := range text {
-		s.SetContent(x+i, y, r, nil, style)
+// PrintAt writes text at (x,y) using a given style. Text beyond the edges of
+// the surface is clipped.
+func PrintAt(s Surface, x, y int, text string, style tcell.Style) {
+	sw, sh := s.Size()
+	if y < 0 || y >= sh {
+		return
+	}
+	i := 0
+	for _, r := range text {
+		if x+i >= 0 && x+i < sw {
+			s.SetContent(x+i, y, r, nil, style)
+		}
+		i++
 	}
 }
 
 // PrintCentered centers text within a specified box (if boxWidth==0, uses full width).
-func PrintCentered(s tcell.Screen, y, offsetX, boxWidth int, text string, style tcell.Style) {
+func PrintCentered(s Surface, y, offsetX, boxWidth int, text string, style tcell.Style) {
 	sw, _ := s.Size()
 	if boxWidth == 0 {
-		x := (sw - len(text)) / 2
+		x := (sw - utf8.RuneCountInString(text)) / 2
 		PrintAt(s, x, y, text, style)
 		return
 	}
-	x := offsetX + (boxWidth-len(text))/2
+	x := offsetX + (boxWidth-utf8.RuneCountInString(text))/2
 	PrintAt(s, x, y, text, style)
 }
 
@@ -34,7 +44,7 @@ func GetFillChar(options DrawOptions) rune {
 }
 
 // PrintMenuTitle prints a menu title with its hotkey underlined or highlighted
-func PrintMenuTitle(s tcell.Screen, x, y int, title string, hotkey rune, style tcell.Style) {
+func PrintMenuTitle(s Surface, x, y int, title string, hotkey rune, style tcell.Style) {
 	// Find the position of the hotkey in the title
 	hotkeyPos := strings.IndexRune(strings.ToLower(title), hotkey)
 	if hotkeyPos >= 0 {
@@ -58,12 +68,25 @@ func PrintMenuTitle(s tcell.Screen, x, y int, title string, hotkey rune, style t
 }
 
 // FillBox fills a rectangular area with the specified background color (no border).
-func FillBox(s tcell.Screen, x, y, w, h int, bgColor tcell.Color, options DrawOptions) {
+// The area is clipped to the surface.
+func FillBox(s Surface, x, y, w, h int, bgColor tcell.Color, options DrawOptions) {
 	fillStyle := tcell.StyleDefault.Background(bgColor)
 	fillChar := GetFillChar(options)
-	for j := y; j < y+h; j++ {
-		for i := x; i < x+w; i++ {
+	r := clipRect(s, Rect{X: x, Y: y, Width: w, Height: h})
+	for j := r.Y; j < r.Y+r.Height; j++ {
+		for i := r.X; i < r.X+r.Width; i++ {
 			s.SetContent(i, j, fillChar, nil, fillStyle)
 		}
 	}
 }
+
+// clipRect returns the part of r that lies on the surface
+func clipRect(s Surface, r Rect) Rect {
+	sw, sh := s.Size()
+	x0, y0 := max(r.X, 0), max(r.Y, 0)
+	x1, y1 := min(r.X+r.Width, sw), min(r.Y+r.Height, sh)
+	if x0 >= x1 || y0 >= y1 {
+		return Rect{}
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
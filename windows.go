@@ -24,21 +24,37 @@ const (
 
 // Window represents a resizable, movable window in the UI
 type Window struct {
-	Title      string
-	X          int
-	Y          int
-	Width      int
-	Height     int
-	MinWidth   int
-	MinHeight  int
-	State      WindowState
-	Visible    bool
-	Active     bool
-	Dragging   bool
-	Resizing   bool
-	LastMouseX int
-	LastMouseY int
-	Content    func(s tcell.Screen, x, y, width, height int) // Function to draw window content
+	Title       string
+	X           int
+	Y           int
+	Width       int
+	Height      int
+	MinWidth    int
+	MinHeight   int
+	State       WindowState
+	Visible     bool
+	Active      bool
+	Dragging    bool
+	Resizing    bool
+	LastMouseX  int
+	LastMouseY  int
+	Content     func(s tcell.Screen, x, y, width, height int) // Function to draw window content
+	ContextMenu *ContextMenu                                  // Opened by a right click inside the window, if set
+	Border      BorderStyle                                   // Border characters; double lines when unset
+
+	// Content larger than the window is drawn off-screen at this size and
+	// scrolled through; 0 uses the size of the content area
+	VirtualWidth  int
+	VirtualHeight int
+	ScrollX       int // Column of the content shown at the left edge
+	ScrollY       int // Row of the content shown at the top edge
+
+	canvas      *Canvas            // Off-screen content of a scrolling window
+	menuTrigger ContextMenuTrigger // Opens ContextMenu
+
+	// Screen size from the last draw or resize, used when no screen is at hand
+	screenWidth  int
+	screenHeight int
 }
 
 // NewWindow creates a new window with default values
@@ -63,6 +79,8 @@ func (w *Window) Draw(s tcell.Screen, borderFg, borderBg, titleFg, titleBg, cont
 		return
 	}
 
+	w.screenWidth, w.screenHeight = s.Size()
+
 	// Adjust x, y, width, height according to window state
 	x, y, width, height := w.GetDimensions(s)
 
@@ -76,7 +94,11 @@ func (w *Window) Draw(s tcell.Screen, borderFg, borderBg, titleFg, titleBg, cont
 	FillBox(s, x, y, width, height, borderBg, fillOptions)
 
 	// Draw the window border
-	DrawWindowBox(s, x, y, width, height, w.Title, w.Active, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+	border := w.Border
+	if border == (BorderStyle{}) {
+		border = BorderDouble
+	}
+	drawWindowBox(s, x, y, width, height, w.Title, w.Active, border, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
 
 	// Draw window content if defined
 	if w.Content != nil {
@@ -85,17 +107,95 @@ func (w *Window) Draw(s tcell.Screen, borderFg, borderBg, titleFg, titleBg, cont
 		contentY := y + 1
 		contentWidth := width - 2
 		contentHeight := height - 2
-		w.Content(s, contentX, contentY, contentWidth, contentHeight)
+		if w.scrolls() {
+			w.drawScrolled(s, contentX, contentY, contentWidth, contentHeight, borderFg, borderBg)
+		} else {
+			w.Content(s, contentX, contentY, contentWidth, contentHeight)
+		}
+	}
+
+	// An open context menu is drawn over the window
+	if w.ContextMenu != nil {
+		w.ContextMenu.Draw(s)
+	}
+}
+
+// scrolls reports whether the content is larger than the content area
+func (w *Window) scrolls() bool {
+	_, _, width, height := w.GetDimensions(nil)
+	return w.VirtualWidth > width-2 || w.VirtualHeight > height-2
+}
+
+// drawScrolled draws the content into an off-screen canvas of the virtual size
+// and copies the visible part into the content area, with scroll bars on the
+// right and bottom borders
+func (w *Window) drawScrolled(s tcell.Screen, x, y, width, height int, borderFg, borderBg tcell.Color) {
+	vw, vh := max(w.VirtualWidth, width), max(w.VirtualHeight, height)
+	if w.canvas == nil {
+		w.canvas = NewCanvas(vw, vh)
+	}
+	w.canvas.Resize(vw, vh)
+	w.canvas.Clear()
+	FillBox(w.canvas, 0, 0, vw, vh, borderBg, DrawOptions{FillRune: ' '})
+	w.Content(w.canvas.Screen(s), 0, 0, vw, vh)
+
+	w.ScrollTo(w.ScrollX, w.ScrollY)
+	w.canvas.Blit(s, x, y, Rect{X: w.ScrollX, Y: w.ScrollY, Width: width, Height: height})
+
+	style := tcell.StyleDefault.Foreground(borderFg).Background(borderBg)
+	if vh > height {
+		drawScrollBar(s, x+width, y, height, w.ScrollY, height, vh, true, style)
+	}
+	if vw > width {
+		drawScrollBar(s, x, y+height, width, w.ScrollX, width, vw, false, style)
+	}
+}
+
+// drawScrollBar draws a scroll bar of length cells from (x, y), down or
+// across, for a view of size view at offset into content of size total
+func drawScrollBar(s Surface, x, y, length, offset, view, total int, vertical bool, style tcell.Style) {
+	if length < 1 || total <= view {
+		return
+	}
+	thumb := max(1, length*view/total)
+	start := min(length-thumb, (length-thumb)*offset/max(1, total-view))
+	for i := 0; i < length; i++ {
+		r := '░'
+		if i >= start && i < start+thumb {
+			r = '█'
+		}
+		if vertical {
+			s.SetContent(x, y+i, r, nil, style)
+		} else {
+			s.SetContent(x+i, y, r, nil, style)
+		}
 	}
 }
 
-// GetDimensions returns the actual dimensions of the window based on its state
+// ScrollTo scrolls the content of a scrolling window so that (x, y) is at the
+// top-left of the content area, as far as the content allows
+func (w *Window) ScrollTo(x, y int) {
+	_, _, width, height := w.GetDimensions(nil)
+	w.ScrollX = max(0, min(x, w.VirtualWidth-(width-2)))
+	w.ScrollY = max(0, min(y, w.VirtualHeight-(height-2)))
+}
+
+// ScrollBy scrolls the content of a scrolling window by (dx, dy) cells
+func (w *Window) ScrollBy(dx, dy int) {
+	w.ScrollTo(w.ScrollX+dx, w.ScrollY+dy)
+}
+
+// GetDimensions returns the actual dimensions of the window based on its state.
+// If s is nil, the screen size seen by the last Draw or Clamp is used.
 func (w *Window) GetDimensions(s tcell.Screen) (x, y, width, height int) {
 	switch w.State {
 	case windowStateNormal:
 		return w.X, w.Y, w.Width, w.Height
 	case windowStateMaximized:
-		screenWidth, screenHeight := s.Size()
+		screenWidth, screenHeight := w.screenWidth, w.screenHeight
+		if s != nil {
+			screenWidth, screenHeight = s.Size()
+		}
 		return 0, 1, screenWidth, screenHeight - 2 // Leave space for menu bar and status bar
 	case windowStateMinimized:
 		// For minimized, we still need to return something reasonable
@@ -106,6 +206,22 @@ func (w *Window) GetDimensions(s tcell.Screen) (x, y, width, height int) {
 	}
 }
 
+// Clamp keeps the window on a screen of the given size, shrinking it (down to
+// its minimum size) if it no longer fits and moving it back into view
+func (w *Window) Clamp(screenWidth, screenHeight int) {
+	w.screenWidth, w.screenHeight = screenWidth, screenHeight
+
+	if w.Width > screenWidth {
+		w.Width = max(w.MinWidth, screenWidth)
+	}
+	if w.Height > screenHeight {
+		w.Height = max(w.MinHeight, screenHeight)
+	}
+
+	w.X = max(0, min(w.X, screenWidth-w.Width))
+	w.Y = max(0, min(w.Y, screenHeight-w.Height))
+}
+
 // HandleEvent processes mouse events for the window
 func (w *Window) HandleEvent(ev tcell.Event, windows []*Window) bool {
 	if !w.Visible {
@@ -228,7 +344,14 @@ func (w *Window) HandleEvent(ev tcell.Event, windows []*Window) bool {
 }
 
 // DrawWindowBox draws a window with title and control buttons
-func DrawWindowBox(s tcell.Screen, x, y, width, height int, title string, active bool,
+func DrawWindowBox(s Surface, x, y, width, height int, title string, active bool,
+	borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) {
+
+	drawWindowBox(s, x, y, width, height, title, active, BorderDouble, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+}
+
+// drawWindowBox draws a window box with the given border
+func drawWindowBox(s Surface, x, y, width, height int, title string, active bool, border BorderStyle,
 	borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) {
 
 	if width < 10 || height < 3 {
@@ -249,14 +372,16 @@ func DrawWindowBox(s tcell.Screen, x, y, width, height int, title string, active
 
 	// Fill the inner content area with background color
 	fillStyle := tcell.StyleDefault.Background(currentBorderBg)
-	for j := y + 1; j < y+height-1; j++ {
-		for i := x + 1; i < x+width-1; i++ {
+	inner := clipRect(s, Rect{X: x + 1, Y: y + 1, Width: width - 2, Height: height - 2})
+	for j := inner.Y; j < inner.Y+inner.Height; j++ {
+		for i := inner.X; i < inner.X+inner.Width; i++ {
 			s.SetContent(i, j, ' ', nil, fillStyle)
 		}
 	}
 
 	// Draw the control buttons
-	drawControlButtons(s, x, y, width, currentBorderFg, currentBorderBg, controlFg, controlBg)
+	border = border.Fallback(s)
+	drawControlButtons(s, x, y, width, border.Top, currentBorderFg, currentBorderBg, controlFg, controlBg)
 
 	// Draw the title
 	titleSt := tcell.StyleDefault.Foreground(currentTitleFg).Background(currentTitleBg)
@@ -265,41 +390,41 @@ func DrawWindowBox(s tcell.Screen, x, y, width, height int, title string, active
 
 	// Draw top border (except where the title and controls are)
 	borderSt := tcell.StyleDefault.Foreground(currentBorderFg).Background(currentBorderBg)
-	s.SetContent(x, y, '╔', nil, borderSt)
+	s.SetContent(x, y, border.TopLeft, nil, borderSt)
 
 	// Top border before title
 	for i := 1; i < 2; i++ {
-		s.SetContent(x+i, y, '═', nil, borderSt)
+		s.SetContent(x+i, y, border.Top, nil, borderSt)
 	}
 
 	// Top border after title
 	buttonStart := width - 21 // Start of minimize/maximize/close buttons
 	for i := 2 + len(titleWithBrackets); i < buttonStart; i++ {
-		s.SetContent(x+i, y, '═', nil, borderSt)
+		s.SetContent(x+i, y, border.Top, nil, borderSt)
 	}
 
 	// Right top corner
-	s.SetContent(x+width-1, y, '╗', nil, borderSt)
+	s.SetContent(x+width-1, y, border.TopRight, nil, borderSt)
 
 	// Left and right borders
 	for j := 1; j < height-1; j++ {
-		s.SetContent(x, y+j, '║', nil, borderSt)
-		s.SetContent(x+width-1, y+j, '║', nil, borderSt)
+		s.SetContent(x, y+j, border.Left, nil, borderSt)
+		s.SetContent(x+width-1, y+j, border.Right, nil, borderSt)
 	}
 
 	// Bottom border with corners
-	s.SetContent(x, y+height-1, '╚', nil, borderSt)
+	s.SetContent(x, y+height-1, border.BottomLeft, nil, borderSt)
 	for i := 1; i < width-1; i++ {
-		s.SetContent(x+i, y+height-1, '═', nil, borderSt)
+		s.SetContent(x+i, y+height-1, border.Bottom, nil, borderSt)
 	}
-	s.SetContent(x+width-1, y+height-1, '╝', nil, borderSt)
+	s.SetContent(x+width-1, y+height-1, border.BottomRight, nil, borderSt)
 
 	// Make the bottom-right corner a special character for resizing
-	s.SetContent(x+width-1, y+height-1, '╬', nil, borderSt)
+	s.SetContent(x+width-1, y+height-1, border.Grip, nil, borderSt)
 }
 
 // drawControlButtons draws the minimize, maximize, and close buttons
-func drawControlButtons(s tcell.Screen, x, y, width int,
+func drawControlButtons(s Surface, x, y, width int, line rune,
 	borderFg, borderBg, controlFg, controlBg tcell.Color) {
 
 	borderSt := tcell.StyleDefault.Foreground(borderFg).Background(borderBg)
@@ -308,7 +433,7 @@ func drawControlButtons(s tcell.Screen, x, y, width int,
 	// Minimize button
 	minX := width - 21
 	for i := 0; i < 2; i++ {
-		s.SetContent(x+minX+i, y, '═', nil, borderSt)
+		s.SetContent(x+minX+i, y, line, nil, borderSt)
 	}
 	s.SetContent(x+minX+2, y, '[', nil, borderSt)
 	s.SetContent(x+minX+3, y, ' ', nil, borderSt)
@@ -319,7 +444,7 @@ func drawControlButtons(s tcell.Screen, x, y, width int,
 	// Maximize button
 	maxX := width - 14
 	for i := 0; i < 2; i++ {
-		s.SetContent(x+maxX+i, y, '═', nil, borderSt)
+		s.SetContent(x+maxX+i, y, line, nil, borderSt)
 	}
 	s.SetContent(x+maxX+2, y, '[', nil, borderSt)
 	s.SetContent(x+maxX+3, y, ' ', nil, borderSt)
@@ -330,7 +455,7 @@ func drawControlButtons(s tcell.Screen, x, y, width int,
 	// Close button
 	closeX := width - 7
 	for i := 0; i < 2; i++ {
-		s.SetContent(x+closeX+i, y, '═', nil, borderSt)
+		s.SetContent(x+closeX+i, y, line, nil, borderSt)
 	}
 	s.SetContent(x+closeX+2, y, '[', nil, borderSt)
 	s.SetContent(x+closeX+3, y, ' ', nil, borderSt)
@@ -339,10 +464,41 @@ func drawControlButtons(s tcell.Screen, x, y, width int,
 	s.SetContent(x+closeX+6, y, ']', nil, borderSt)
 }
 
-// ManageWindows handles window z-order and event routing to appropriate windows
+// ClampWindows keeps all windows on screen after a resize
+func ClampWindows(s tcell.Screen, windows []*Window) {
+	screenWidth, screenHeight := s.Size()
+	for _, window := range windows {
+		window.Clamp(screenWidth, screenHeight)
+	}
+}
+
+// ManageWindows handles window z-order and event routing to appropriate windows,
+// and redraws the windows when an event was handled.
+// Resize events clamp the windows to the new screen size but are not reported
+// as handled, so the caller still redraws the whole screen.
 func ManageWindows(s tcell.Screen, windows []*Window, ev tcell.Event,
 	borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) bool {
 
+	if !HandleWindows(s, windows, ev) {
+		return false
+	}
+	DrawWindows(s, windows, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+	return true
+}
+
+// HandleWindows is ManageWindows without the redraw, for callers that draw
+// through a Compositor. A window that handles the event is moved to the end
+// of windows, the top of the z-order.
+func HandleWindows(s tcell.Screen, windows []*Window, ev tcell.Event) bool {
+	if _, ok := ev.(*tcell.EventResize); ok {
+		ClampWindows(s, windows)
+		return false
+	}
+
+	if handleWindowContextMenus(s, windows, ev) {
+		return true
+	}
+
 	// Start from the top window (last in the array) and work backwards
 	for i := len(windows) - 1; i >= 0; i-- {
 		window := windows[i]
@@ -350,16 +506,7 @@ func ManageWindows(s tcell.Screen, windows []*Window, ev tcell.Event,
 		// Try to handle the event with this window
 		if window.HandleEvent(ev, windows) {
 			// If the event was handled, move this window to the top of the z-order
-			if i < len(windows)-1 {
-				// Remove the window from its current position
-				windowToMove := windows[i]
-				windows = append(windows[:i], windows[i+1:]...)
-				// Add it to the end (top of z-order)
-				windows = append(windows, windowToMove)
-			}
-
-			// Redraw all windows
-			DrawWindows(s, windows, borderFg, borderBg, titleFg, titleBg, controlFg, controlBg)
+			raiseWindow(windows, i)
 			return true
 		}
 	}
@@ -367,6 +514,64 @@ func ManageWindows(s tcell.Screen, windows []*Window, ev tcell.Event,
 	return false
 }
 
+// handleWindowContextMenus routes events to an open window context menu, or
+// opens the context menu of the window under a right click. Shift+F10 opens
+// the menu of the top-most window.
+func handleWindowContextMenus(s tcell.Screen, windows []*Window, ev tcell.Event) bool {
+	// Every window follows the mouse buttons; a window added while a button
+	// was held then does not mistake the next move for a press
+	x, y, ok := -1, -1, len(windows) > 0
+	for _, window := range windows {
+		wx, wy, wok := window.menuTrigger.Check(ev)
+		x, y, ok = wx, wy, ok && wok
+	}
+
+	for _, window := range windows {
+		if window.ContextMenu != nil && window.ContextMenu.IsOpen() {
+			return window.ContextMenu.HandleEvent(s, ev)
+		}
+	}
+
+	if !ok {
+		return false
+	}
+	for i := len(windows) - 1; i >= 0; i-- {
+		window := windows[i]
+		if !window.Visible {
+			continue
+		}
+		wx, wy, width, height := window.GetDimensions(s)
+		if x < 0 {
+			// Keyboard: open at the top-left of the top-most window's content
+			if window.ContextMenu == nil {
+				return false
+			}
+			raiseWindow(windows, i)
+			window.ContextMenu.Show(wx+1, wy+1)
+			return true
+		}
+		if x >= wx && x < wx+width && y >= wy && y < wy+height {
+			if window.ContextMenu == nil {
+				return false
+			}
+			// The menu is drawn with its window, which must be on top to
+			// keep other windows from covering it
+			window.Active = true
+			raiseWindow(windows, i)
+			window.ContextMenu.Show(x, y)
+			return true
+		}
+	}
+	return false
+}
+
+// raiseWindow moves windows[i] to the end of windows, the top of the z-order
+func raiseWindow(windows []*Window, i int) {
+	w := windows[i]
+	copy(windows[i:], windows[i+1:])
+	windows[len(windows)-1] = w
+}
+
 // DrawWindows draws all visible windows in z-order
 func DrawWindows(s tcell.Screen, windows []*Window,
 	borderFg, borderBg, titleFg, titleBg, controlFg, controlBg tcell.Color) {